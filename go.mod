@@ -5,18 +5,22 @@ go 1.18
 require (
 	github.com/adrg/xdg v0.5.3
 	github.com/gdamore/tcell/v2 v2.7.1
+	github.com/graarh/golang-socketio v0.0.0-20170510162725-2c44953b9b5f
 	github.com/rivo/tview v0.0.0-20250501113434-0c592cd31026
 	github.com/rivo/uniseg v0.4.7
 	github.com/ymattw/googs v0.0.0-20251125200803-2b0d7f7cb624
+	golang.org/x/crypto v0.21.0
+	golang.org/x/term v0.18.0
 )
 
 require (
 	github.com/gdamore/encoding v1.0.0 // indirect
 	github.com/gorilla/websocket v1.5.3 // indirect
-	github.com/graarh/golang-socketio v0.0.0-20170510162725-2c44953b9b5f // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	golang.org/x/sys v0.26.0 // indirect
-	golang.org/x/term v0.17.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 )
+
+// Configurable server endpoints and Client.Connect(), until released upstream
+replace github.com/ymattw/googs => ./third_party/googs
@@ -0,0 +1,144 @@
+// Package cli implements non-interactive subcommands for scripting, on top of
+// a saved and authenticated googs.Client.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ymattw/googs"
+)
+
+// Exit codes
+const (
+	ExitOK      = 0
+	ExitError   = 1
+	ExitUsage   = 2
+	ExitTimeout = 3
+)
+
+var errTimeout = errors.New("timed out")
+
+type command struct {
+	usage string
+	flags func(fs *flag.FlagSet) // Extra flags besides --json, optional
+	run   func(c *googs.Client, fs *flag.FlagSet, args []string) error
+}
+
+var commands = map[string]command{
+	"games": {"games [--json]", nil, runGames},
+	"show":  {"show [--json] <game-id>", nil, runShow},
+	"move":  {"move [--json] <game-id> <coordinate>", nil, runMove},
+	"pass":  {"pass [--json] <game-id>", nil, runPass},
+	"chat":  {"chat [--json] <game-id> <message>", nil, runChat},
+	"wait": {"wait [--json] [--interval 30s] [--timeout 0]", func(fs *flag.FlagSet) {
+		fs.Duration("interval", 30*time.Second, "Check active games at this interval")
+		fs.Duration("timeout", 0, "Give up after this long, 0 means never")
+	}, runWait},
+}
+
+// Order of commands in usage
+var commandNames = []string{"games", "show", "move", "pass", "chat", "wait"}
+
+// IsCommand tells whether the name is a subcommand.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Usage prints subcommands usage.
+func Usage(w io.Writer) {
+	fmt.Fprintf(w, "Subcommands:\n")
+	for _, name := range commandNames {
+		fmt.Fprintf(w, "  tenuki %s\n", commands[name].usage)
+	}
+}
+
+// Run runs the subcommand, returns exit code.
+func Run(c *googs.Client, args []string) int {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		Usage(os.Stderr)
+		return ExitUsage
+	}
+	if !c.LoggedIn() {
+		fmt.Fprintln(os.Stderr, "Not logged in, run tenuki without subcommand to login first")
+		return ExitError
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintf(os.Stderr, "Usage: tenuki %s\n", cmd.usage) }
+	fs.Bool("json", false, "Print output in JSON")
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return ExitUsage
+	}
+
+	err = cmd.run(c, fs, positional)
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, flag.ErrHelp):
+		fs.Usage()
+		return ExitUsage
+	case errors.Is(err, errTimeout):
+		fmt.Fprintln(os.Stderr, err)
+		return ExitTimeout
+	}
+	fmt.Fprintln(os.Stderr, err)
+	return ExitError
+}
+
+// Parse flags anywhere in args unless after "--", return positional args
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if args = rest; len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func jsonOutput(fs *flag.FlagSet) bool {
+	return fs.Lookup("json").Value.(flag.Getter).Get().(bool)
+}
+
+// Print v as JSON, or call text to print in text
+func output(fs *flag.FlagSet, v any, text func()) error {
+	if !jsonOutput(fs) {
+		text()
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func parseGameID(args []string, want int) (int64, error) {
+	if len(args) != want {
+		return 0, flag.ErrHelp
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid game ID %q", args[0])
+	}
+	return id, nil
+}
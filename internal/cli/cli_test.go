@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		args           []string
+		wantPositional []string
+		wantJSON       bool
+		wantInterval   time.Duration
+		wantErr        bool
+	}{
+		{nil, nil, false, 30 * time.Second, false},
+		{[]string{"123"}, []string{"123"}, false, 30 * time.Second, false},
+		{[]string{"--json", "123", "D4"}, []string{"123", "D4"}, true, 30 * time.Second, false},
+		{[]string{"123", "--json", "D4"}, []string{"123", "D4"}, true, 30 * time.Second, false},
+		{[]string{"123", "D4", "-json"}, []string{"123", "D4"}, true, 30 * time.Second, false},
+		{[]string{"--interval", "1m", "123"}, []string{"123"}, false, time.Minute, false},
+		{[]string{"123", "--interval=5s"}, []string{"123"}, false, 5 * time.Second, false},
+		{[]string{"123", "--", "--json", "-x"}, []string{"123", "--json", "-x"}, false, 30 * time.Second, false},
+		{[]string{"--", "-1"}, []string{"-1"}, false, 30 * time.Second, false},
+		{[]string{"123", "--unknown"}, nil, false, 0, true},
+		{[]string{"123", "--interval"}, nil, false, 0, true},
+		{[]string{"--interval", "soon"}, nil, false, 0, true},
+	}
+	for _, tc := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		json := fs.Bool("json", false, "")
+		interval := fs.Duration("interval", 30*time.Second, "")
+		got, err := parseInterspersed(fs, tc.args)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseInterspersed(%q) error %v, want error %v", tc.args, err, tc.wantErr)
+			continue
+		}
+		if tc.wantErr {
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.wantPositional) || *json != tc.wantJSON || *interval != tc.wantInterval {
+			t.Errorf("parseInterspersed(%q) = %q, json %v, interval %s, want %q, %v, %s",
+				tc.args, got, *json, *interval, tc.wantPositional, tc.wantJSON, tc.wantInterval)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+// Column letters, note 'I' is skipped
+const columns = "ABCDEFGHJKLMNOPQRSTUVWXYZ"
+
+// How long to wait for the server to confirm a move or chat
+const confirmTimeout = 10 * time.Second
+
+type gameSummary struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Opponent   string `json:"opponent"`
+	MyTurn     bool   `json:"my_turn"`
+	MoveNumber int    `json:"move_number"`
+	Size       int    `json:"size"`
+	Speed      string `json:"speed"`
+	Clock      string `json:"clock"`
+}
+
+func summarize(g *googs.Game, myUserID int64) gameSummary {
+	turn := util.Cond(g.Clock.CurrentPlayerID == g.Players.Black.ID, googs.PlayerBlack, googs.PlayerWhite)
+	return gameSummary{
+		ID:         g.GameID,
+		Name:       g.GameName,
+		Opponent:   g.Opponent(myUserID).String(),
+		MyTurn:     g.IsMyTurn(myUserID),
+		MoveNumber: len(g.Moves),
+		Size:       g.BoardSize(),
+		Speed:      g.TimeControl.Speed,
+		Clock:      g.Clock.ComputeClock(&g.TimeControl, turn).String(),
+	}
+}
+
+func printSummaries(games []gameSummary) {
+	for _, g := range games {
+		fmt.Printf("%s %-10d %3d moves  %-24s %-30s %s\n",
+			util.Cond(g.MyTurn, "*", " "), g.ID, g.MoveNumber, g.Opponent, g.Name, g.Clock)
+	}
+}
+
+func activeGames(c *googs.Client) ([]gameSummary, error) {
+	ov, err := ogs.Overview(c)
+	if err != nil {
+		return nil, err
+	}
+	games := []gameSummary{}
+	for i := range ov.ActiveGames {
+		games = append(games, summarize(&ov.ActiveGames[i].Game, c.UserID))
+	}
+	return games, nil
+}
+
+// List active games, games on my turn are marked with "*"
+func runGames(c *googs.Client, fs *flag.FlagSet, args []string) error {
+	if len(args) != 0 {
+		return flag.ErrHelp
+	}
+	games, err := activeGames(c)
+	if err != nil {
+		return err
+	}
+	return output(fs, games, func() { printSummaries(games) })
+}
+
+type boardView struct {
+	ID           int64    `json:"id"`
+	Name         string   `json:"name"`
+	Black        string   `json:"black"`
+	White        string   `json:"white"`
+	Phase        string   `json:"phase"`
+	MoveNumber   int      `json:"move_number"`
+	PlayerToMove int64    `json:"player_to_move"`
+	MyTurn       bool     `json:"my_turn"`
+	LastMove     string   `json:"last_move"` // Empty for none or pass
+	Outcome      string   `json:"outcome,omitempty"`
+	Board        []string `json:"board"` // Top row first, X=black, O=white
+}
+
+// Print the board as text
+func runShow(c *googs.Client, fs *flag.FlagSet, args []string) error {
+	id, err := parseGameID(args, 1)
+	if err != nil {
+		return err
+	}
+	g, err := ogs.Game(c, id)
+	if err != nil {
+		return err
+	}
+	state, err := ogs.GameState(c, id)
+	if err != nil {
+		return err
+	}
+
+	v := boardView{
+		ID:           id,
+		Name:         g.GameName,
+		Black:        g.Players.Black.String(),
+		White:        g.Players.White.String(),
+		Phase:        string(state.Phase),
+		MoveNumber:   state.MoveNumber,
+		PlayerToMove: state.PlayerToMove,
+		MyTurn:       state.IsMyTurn(c.UserID),
+		Outcome:      state.Outcome,
+	}
+	if a1, err := state.LastMove.ToA1Coordinate(state.BoardSize()); err == nil {
+		v.LastMove = a1.String()
+	}
+	for _, row := range state.Board {
+		var b strings.Builder
+		for _, stone := range row {
+			b.WriteByte(".XO"[stone%3])
+		}
+		v.Board = append(v.Board, b.String())
+	}
+
+	return output(fs, v, func() {
+		size := state.BoardSize()
+		fmt.Printf("Game %d %q: %s (X) vs %s (O)\n", v.ID, v.Name, v.Black, v.White)
+		fmt.Printf("%s phase, %d moves", v.Phase, v.MoveNumber)
+		if v.LastMove != "" {
+			fmt.Printf(", last move %s", v.LastMove)
+		}
+		if state.Phase == googs.PlayPhase {
+			fmt.Printf(", %s to play%s", g.WhoseTurn(state), util.Cond(v.MyTurn, " (your turn)", ""))
+		}
+		if v.Outcome != "" {
+			fmt.Printf(", %s", g.Result())
+		}
+		fmt.Println()
+
+		header := "   " + strings.Join(strings.Split(columns[:size], ""), " ")
+		fmt.Println(header)
+		for y, row := range v.Board {
+			fmt.Printf("%2d %s %d\n", size-y, strings.Join(strings.Split(row, ""), " "), size-y)
+		}
+		fmt.Println(header)
+	})
+}
+
+type moveResult struct {
+	ID         int64  `json:"id"`
+	Move       string `json:"move"` // "pass" for passing
+	MoveNumber int    `json:"move_number"`
+}
+
+// Load game state and make sure it's my turn to play
+func myTurnState(c *googs.Client, id int64) (*googs.GameState, error) {
+	state, err := ogs.GameState(c, id)
+	if err != nil {
+		return nil, err
+	}
+	if state.Phase != googs.PlayPhase {
+		return nil, fmt.Errorf("game %d is in %s phase", id, state.Phase)
+	}
+	if !state.IsMyTurn(c.UserID) {
+		return nil, fmt.Errorf("not your turn in game %d", id)
+	}
+	return state, nil
+}
+
+// Submit a move and wait for the server to confirm
+func play(c *googs.Client, fs *flag.FlagSet, id int64, state *googs.GameState, move string, submit func() error) error {
+	done := make(chan *googs.GameMove, 1)
+	if err := c.OnMove(id, func(m *googs.GameMove) {
+		if m.MoveNumber > state.MoveNumber {
+			select {
+			case done <- m:
+			default:
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if err := c.GameConnect(id); err != nil {
+		return err
+	}
+	defer c.GameDisconnect(id)
+	if err := submit(); err != nil {
+		return err
+	}
+
+	select {
+	case m := <-done:
+		res := moveResult{ID: id, Move: move, MoveNumber: m.MoveNumber}
+		return output(fs, res, func() {
+			fmt.Printf("Played %s in game %d, move %d\n", move, id, m.MoveNumber)
+		})
+	case <-time.After(confirmTimeout):
+		return fmt.Errorf("move %s in game %d not confirmed, maybe illegal: %w", move, id, errTimeout)
+	}
+}
+
+// Play a move in A1 notation, e.g. "D4"
+func runMove(c *googs.Client, fs *flag.FlagSet, args []string) error {
+	id, err := parseGameID(args, 2)
+	if err != nil {
+		return err
+	}
+	a1, err := googs.NewA1Coordinate(args[1])
+	if err != nil {
+		return err
+	}
+	state, err := myTurnState(c, id)
+	if err != nil {
+		return err
+	}
+	o, err := a1.ToOriginCoordinate(state.BoardSize())
+	if err != nil {
+		return err
+	}
+	if state.Board[o.Y][o.X] != 0 {
+		return fmt.Errorf("%s is occupied in game %d", a1, id)
+	}
+	return play(c, fs, id, state, strings.ToUpper(a1.String()), func() error {
+		return c.GameMove(id, o.X, o.Y)
+	})
+}
+
+func runPass(c *googs.Client, fs *flag.FlagSet, args []string) error {
+	id, err := parseGameID(args, 1)
+	if err != nil {
+		return err
+	}
+	state, err := myTurnState(c, id)
+	if err != nil {
+		return err
+	}
+	return play(c, fs, id, state, "pass", func() error {
+		return c.PassTurn(id)
+	})
+}
+
+type chatResult struct {
+	ID         int64  `json:"id"`
+	MoveNumber int    `json:"move_number"`
+	Body       string `json:"body"`
+}
+
+// Send a chat message to the game, words are joined by spaces
+func runChat(c *googs.Client, fs *flag.FlagSet, args []string) error {
+	if len(args) < 2 {
+		return flag.ErrHelp
+	}
+	id, err := parseGameID(args[:1], 1)
+	if err != nil {
+		return err
+	}
+	body := strings.TrimSpace(strings.Join(args[1:], " "))
+	if body == "" {
+		return fmt.Errorf("empty message")
+	}
+	state, err := ogs.GameState(c, id)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the message to be echoed back
+	done := make(chan struct{}, 1)
+	if err := c.OnGameChat(id, func(chat *googs.GameChat) {
+		if chat.Line.PlayerID == c.UserID && chat.Line.Body == body {
+			select {
+			case done <- struct{}{}:
+			default:
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if err := c.GameConnect(id); err != nil {
+		return err
+	}
+	defer c.GameDisconnect(id)
+	if err := c.GameChat(id, state.MoveNumber, body); err != nil {
+		return err
+	}
+
+	select {
+	case <-done:
+		res := chatResult{ID: id, MoveNumber: state.MoveNumber, Body: body}
+		return output(fs, res, func() {
+			fmt.Printf("Sent to game %d at move %d\n", id, state.MoveNumber)
+		})
+	case <-time.After(confirmTimeout):
+		return fmt.Errorf("message to game %d not confirmed: %w", id, errTimeout)
+	}
+}
+
+// Block until it's my turn in any active game, print those games
+func runWait(c *googs.Client, fs *flag.FlagSet, args []string) error {
+	if len(args) != 0 {
+		return flag.ErrHelp
+	}
+	interval := fs.Lookup("interval").Value.(flag.Getter).Get().(time.Duration)
+	timeout := fs.Lookup("timeout").Value.(flag.Getter).Get().(time.Duration)
+	if interval < 5*time.Second {
+		return fmt.Errorf("interval %s is too short, minimum 5s", interval)
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		games, err := activeGames(c)
+		if err != nil {
+			return err
+		}
+		myTurn := []gameSummary{}
+		for _, g := range games {
+			if g.MyTurn {
+				myTurn = append(myTurn, g)
+			}
+		}
+		if len(myTurn) > 0 {
+			return output(fs, myTurn, func() { printSummaries(myTurn) })
+		}
+		sleep := interval
+		if !deadline.IsZero() {
+			// Sleep no further than the deadline for a final check there
+			left := time.Until(deadline)
+			if left <= 0 {
+				return fmt.Errorf("no game on your turn: %w", errTimeout)
+			}
+			if left < sleep {
+				sleep = left
+			}
+		}
+		time.Sleep(sleep)
+	}
+}
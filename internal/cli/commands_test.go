@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/ogs"
+)
+
+// Client of a server with a 5x5 game 1 in play, black (ID 7) to move after
+// white played C3
+func newTestClient(t *testing.T) *googs.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/games/1":
+			fmt.Fprint(w, `{"gamedata": {"game_id": 1, "game_name": "Test", "width": 5, "height": 5,
+				"players": {"black": {"id": 7, "username": "tenuki"}, "white": {"id": 8, "username": "sente"}}}}`)
+		case "/termination-api/game/1/state":
+			fmt.Fprint(w, `{"phase": "play", "move_number": 2, "player_to_move": 7, "last_move": {"x": 2, "y": 2},
+				"board": [[0,0,0,0,0], [0,0,0,1,0], [0,0,2,0,0], [0,0,0,0,0], [0,0,0,0,0]]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	c := ogs.NewClient(config.Server{
+		Host:        "127.0.0.1",
+		RESTURL:     srv.URL,
+		RealtimeURL: "ws" + strings.TrimPrefix(srv.URL, "http") + "/socket.io/",
+	}, "id", "secret")
+	c.AccessToken, c.UserID = "token", 7
+	return c
+}
+
+// Run fn and return what it printed to stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = fn()
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestRunShow(t *testing.T) {
+	c := newTestClient(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"1"}, `Game 1 "Test": tenuki[?] (X) vs sente[?] (O)
+play phase, 2 moves, last move C3, Black to play (your turn)
+   A B C D E
+ 5 . . . . . 5
+ 4 . . . X . 4
+ 3 . . O . . 3
+ 2 . . . . . 2
+ 1 . . . . . 1
+   A B C D E
+`},
+		{[]string{"--json", "1"}, `{
+  "id": 1,
+  "name": "Test",
+  "black": "tenuki[?]",
+  "white": "sente[?]",
+  "phase": "play",
+  "move_number": 2,
+  "player_to_move": 7,
+  "my_turn": true,
+  "last_move": "C3",
+  "board": [
+    ".....",
+    "...X.",
+    "..O..",
+    ".....",
+    "....."
+  ]
+}
+`},
+	}
+	for _, tc := range tests {
+		fs := flag.NewFlagSet("show", flag.ContinueOnError)
+		fs.Bool("json", false, "")
+		args, err := parseInterspersed(fs, tc.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := captureStdout(t, func() error { return runShow(c, fs, args) })
+		if got != tc.want {
+			t.Errorf("show %q printed\n%s\nwant\n%s", tc.args, got, tc.want)
+		}
+	}
+
+}
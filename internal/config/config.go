@@ -0,0 +1,284 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+const ConfigFile = "config.json"
+
+// Shortest refresh interval allowed, to be nice to the server
+const minRefreshInterval = 5 * time.Second
+
+// Config is edited by the user, unlike Preferences which are saved by the app.
+type Config struct {
+	Server Server `json:"server"`
+	UI     UI     `json:"ui"`
+	Login  Login  `json:"login"`
+	Hooks  Hooks  `json:"hooks"`
+}
+
+// Host of the OGS server used unless configured
+const DefaultServer = "online-go.com"
+
+// Server is an OGS compatible deployment, URLs are derived from the host
+// unless set. Saved logins are kept per host.
+type Server struct {
+	Host        string `json:"host"`         // e.g. "beta.online-go.com"
+	RESTURL     string `json:"rest_url"`     // e.g. "https://beta.online-go.com"
+	RealtimeURL string `json:"realtime_url"` // e.g. "wss://beta.online-go.com/socket.io/?transport=websocket&EIO=3"
+}
+
+func (s Server) REST() string {
+	if s.RESTURL != "" {
+		return strings.TrimSuffix(s.RESTURL, "/")
+	}
+	return "https://" + s.Host
+}
+
+func (s Server) Realtime() string {
+	if s.RealtimeURL != "" {
+		return s.RealtimeURL
+	}
+	return "wss://" + s.Host + "/socket.io/?transport=websocket&EIO=3"
+}
+
+// UI options of the app.
+type UI struct {
+	Scheme      string                `json:"scheme"`       // UI color scheme, e.g. "solarized-dark"
+	BoardTheme  string                `json:"board_theme"`  // Default board theme, e.g. "night"
+	BoardThemes map[string]BoardTheme `json:"board_themes"` // User defined, by name
+	Glyphs      string                `json:"glyphs"`       // "fullwidth" or "ascii"
+	StartPage   string                `json:"start_page"`   // "home", "watch", "history" or "seek"
+	Alerts      Alerts                `json:"alerts"`
+	Refresh     Refresh               `json:"refresh"`
+	Layout      Layout                `json:"layout"`
+
+	// Key binding overrides, scope => action => keys
+	Keys map[string]map[string][]string `json:"keys"`
+}
+
+// BoardTheme colors are "#rrggbb" or color names like "darkgreen", unset ones
+// are taken from the base theme.
+type BoardTheme struct {
+	Base           string `json:"base"`            // Built-in theme to start from, default "night"
+	Grid           string `json:"grid"`            // Grid lines and hoshi
+	Board          string `json:"board"`           // Board background
+	LastBlack      string `json:"last_black"`      // Background of the last move by black
+	LastWhite      string `json:"last_white"`      // Background of the last move by white
+	CursorBlack    string `json:"cursor_black"`    // Cursor when black to play
+	CursorWhite    string `json:"cursor_white"`    // Cursor when white to play
+	TerritoryBlack string `json:"territory_black"` // Background of black territory when scoring
+	TerritoryWhite string `json:"territory_white"` // Background of white territory when scoring
+	Dead           string `json:"dead"`            // Background of dead stones
+}
+
+// Alerts on a game page.
+type Alerts struct {
+	// Clocks under this are shown in red, and a notification is shown
+	// when own clock runs under it. Zero to disable.
+	ClockLow Duration `json:"clock_low"`
+}
+
+// Refresh intervals of data polled from the server.
+type Refresh struct {
+	Watch      Duration `json:"watch"`      // Watch page game list
+	Challenges Duration `json:"challenges"` // Challenges on the home page
+	Following  Duration `json:"following"`  // Games of followed players
+	Rematch    Duration `json:"rematch"`    // Rematch offers on a finished game page
+}
+
+type Layout struct {
+	HidePreview bool `json:"hide_preview"` // Preview pane of the home page
+	HideChat    bool `json:"hide_chat"`    // Chat of the game page
+}
+
+// Login options.
+type Login struct {
+	// Registered with the OGS application for login in a browser, must be a
+	// loopback http URL with a port
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// Hooks are shell commands run by the daemon on events, with a JSON payload
+// on stdin.
+type Hooks struct {
+	MyTurn       []string `json:"my_turn"`
+	GameFinished []string `json:"game_finished"`
+	Chat         []string `json:"chat"`
+	ClockLow     []string `json:"clock_low"`
+
+	// Clock is low when less than this left, e.g. "1h"
+	ClockLowThreshold Duration `json:"clock_low_threshold"`
+}
+
+// Duration is a time.Duration written as a string in JSON, e.g. "1m30s".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\", got %s", data)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func ConfigPath() string {
+	return filepath.Join(xdg.ConfigHome, "tenuki", ConfigFile)
+}
+
+// Default returns the config used when no config file exists. Fields absent
+// from the config file keep these values.
+func Default() *Config {
+	return &Config{
+		Server: Server{Host: DefaultServer},
+		UI: UI{
+			Scheme:     "solarized-dark",
+			BoardTheme: "night",
+			Glyphs:     "fullwidth",
+			StartPage:  "home",
+			Alerts: Alerts{
+				ClockLow: Duration(30 * time.Second),
+			},
+			Refresh: Refresh{
+				Watch:      Duration(30 * time.Second),
+				Challenges: Duration(30 * time.Second),
+				Following:  Duration(time.Minute),
+				Rematch:    Duration(10 * time.Second),
+			},
+		},
+		Login: Login{RedirectURI: "http://127.0.0.1:8765/callback"},
+		Hooks: Hooks{ClockLowThreshold: Duration(time.Hour)},
+	}
+}
+
+// Load the config, defaults are returned if the file does not exist.
+func LoadConfig() (*Config, error) {
+	c := Default()
+	data, err := os.ReadFile(ConfigPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", ConfigPath(), err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ConfigPath(), err)
+	}
+	return c, nil
+}
+
+// SetServerHost switches to another server, URLs configured for the previous
+// one are dropped.
+func (c *Config) SetServerHost(host string) error {
+	if host == c.Server.Host {
+		return nil
+	}
+	c.Server = Server{Host: host}
+	return c.validate()
+}
+
+func (c *Config) validate() error {
+	if c.Server.Host == "" || strings.ContainsAny(c.Server.Host, "/\\") {
+		return fmt.Errorf("server.host %q must be a host name like %s", c.Server.Host, DefaultServer)
+	}
+	if u, err := url.Parse(c.Server.REST()); err != nil || !oneOf(u.Scheme, "http", "https") || u.Host == "" {
+		return fmt.Errorf("server.rest_url %q must be an http(s) URL", c.Server.RESTURL)
+	}
+	if u, err := url.Parse(c.Server.Realtime()); err != nil || !oneOf(u.Scheme, "ws", "wss") || u.Host == "" {
+		return fmt.Errorf("server.realtime_url %q must be a ws(s) URL", c.Server.RealtimeURL)
+	}
+	ui := &c.UI
+	if !oneOf(ui.Glyphs, "fullwidth", "ascii") {
+		return fmt.Errorf("ui.glyphs %q is not one of fullwidth, ascii", ui.Glyphs)
+	}
+	if !oneOf(ui.StartPage, "home", "watch", "history", "seek") {
+		return fmt.Errorf("ui.start_page %q is not one of home, watch, history, seek", ui.StartPage)
+	}
+	if ui.Alerts.ClockLow < 0 {
+		return fmt.Errorf("ui.alerts.clock_low %s is negative", ui.Alerts.ClockLow.Std())
+	}
+	for name, d := range map[string]Duration{
+		"watch":      ui.Refresh.Watch,
+		"challenges": ui.Refresh.Challenges,
+		"following":  ui.Refresh.Following,
+		"rematch":    ui.Refresh.Rematch,
+	} {
+		if d.Std() < minRefreshInterval {
+			return fmt.Errorf("ui.refresh.%s %s is too short, minimum %s", name, d.Std(), minRefreshInterval)
+		}
+	}
+	if err := validateRedirectURI(c.Login.RedirectURI); err != nil {
+		return fmt.Errorf("login.redirect_uri %q %w", c.Login.RedirectURI, err)
+	}
+	if c.Hooks.ClockLowThreshold <= 0 {
+		return fmt.Errorf("hooks.clock_low_threshold %s must be positive", c.Hooks.ClockLowThreshold.Std())
+	}
+	return nil
+}
+
+func validateRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "http" || u.Port() == "" {
+		return errors.New("must be like http://127.0.0.1:8765/callback")
+	}
+	if ip := net.ParseIP(u.Hostname()); u.Hostname() != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return errors.New("must be on a loopback address")
+	}
+	return nil
+}
+
+func oneOf(s string, choices ...string) bool {
+	for _, c := range choices {
+		if s == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Unix socket of a running app to be driven by other programs, per user.
+func (s Server) ControlSocketPath(username string) string {
+	return filepath.Join(s.userDir(xdg.RuntimeDir, username), "control.sock")
+}
+
+// Directory of per user files under base, e.g. $XDG_STATE_HOME/tenuki/<user>,
+// or $XDG_STATE_HOME/tenuki/servers/<host>/<user> for other servers.
+func (s Server) userDir(base, username string) string {
+	return filepath.Join(s.dir(base), username)
+}
+
+// Files of the default server stay where they were before servers became
+// configurable.
+func (s Server) dir(base string) string {
+	if s.Host == DefaultServer {
+		return filepath.Join(base, "tenuki")
+	}
+	return filepath.Join(base, "tenuki", "servers", s.Host)
+}
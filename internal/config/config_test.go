@@ -0,0 +1,68 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string // Substring, empty for valid
+	}{
+		{"default", func(c *Config) {}, ""},
+		{"custom server", func(c *Config) { c.Server.Host = "beta.online-go.com" }, ""},
+		{"custom urls", func(c *Config) {
+			c.Server.RESTURL = "http://localhost:8080"
+			c.Server.RealtimeURL = "ws://localhost:8080/socket.io/"
+		}, ""},
+		{"empty host", func(c *Config) { c.Server.Host = "" }, "server.host"},
+		{"host with path", func(c *Config) { c.Server.Host = "online-go.com/api" }, "server.host"},
+		{"rest url scheme", func(c *Config) { c.Server.RESTURL = "ftp://online-go.com" }, "server.rest_url"},
+		{"realtime url scheme", func(c *Config) { c.Server.RealtimeURL = "https://online-go.com" }, "server.realtime_url"},
+		{"glyphs", func(c *Config) { c.UI.Glyphs = "emoji" }, "ui.glyphs"},
+		{"start page", func(c *Config) { c.UI.StartPage = "game" }, "ui.start_page"},
+		{"clock low disabled", func(c *Config) { c.UI.Alerts.ClockLow = 0 }, ""},
+		{"negative clock low", func(c *Config) { c.UI.Alerts.ClockLow = Duration(-time.Second) }, "ui.alerts.clock_low"},
+		{"minimum refresh", func(c *Config) { c.UI.Refresh.Watch = Duration(minRefreshInterval) }, ""},
+		{"short refresh", func(c *Config) { c.UI.Refresh.Rematch = Duration(time.Second) }, "ui.refresh.rematch"},
+		{"redirect uri localhost", func(c *Config) { c.Login.RedirectURI = "http://localhost:9000/cb" }, ""},
+		{"redirect uri https", func(c *Config) { c.Login.RedirectURI = "https://127.0.0.1:8765/callback" }, "login.redirect_uri"},
+		{"redirect uri without port", func(c *Config) { c.Login.RedirectURI = "http://127.0.0.1/callback" }, "login.redirect_uri"},
+		{"redirect uri not loopback", func(c *Config) { c.Login.RedirectURI = "http://example.com:8765/callback" }, "loopback"},
+		{"clock low threshold", func(c *Config) { c.Hooks.ClockLowThreshold = 0 }, "hooks.clock_low_threshold"},
+	}
+	for _, tc := range tests {
+		c := Default()
+		tc.modify(c)
+		err := c.validate()
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		case tc.wantErr != "" && err == nil:
+			t.Errorf("%s: no error, want %q", tc.name, tc.wantErr)
+		case tc.wantErr != "" && !strings.Contains(err.Error(), tc.wantErr):
+			t.Errorf("%s: error %v, want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestServerPaths(t *testing.T) {
+	tests := []struct {
+		server Server
+		want   string
+	}{
+		{Server{Host: DefaultServer}, filepath.Join(xdg.StateHome, "tenuki", "tenuki", Secret)},
+		{Server{Host: "beta.online-go.com"}, filepath.Join(xdg.StateHome, "tenuki", "servers", "beta.online-go.com", "tenuki", Secret)},
+	}
+	for _, tc := range tests {
+		if got := tc.server.SecretPath("tenuki"); got != tc.want {
+			t.Errorf("%s: SecretPath() = %q, want %q", tc.server.Host, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	sealedFormat     = "tenuki-sealed-v1"
+	sealedIterations = 600000 // OWASP recommendation for PBKDF2-HMAC-SHA256
+	sealedSaltSize   = 16
+	sealedKeySize    = 32 // AES-256
+)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted file")
+
+// sealed is the JSON envelope of data encrypted with AES-GCM, keyed by a
+// passphrase through PBKDF2-HMAC-SHA256. Byte fields are base64 in JSON.
+type sealed struct {
+	Format     string `json:"format"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Parse data as a sealed envelope, nil if it is not one
+func parseSealed(data []byte) *sealed {
+	var s sealed
+	if err := json.Unmarshal(data, &s); err != nil || s.Format != sealedFormat {
+		return nil
+	}
+	return &s
+}
+
+func seal(plain []byte, passphrase string) ([]byte, error) {
+	s := sealed{
+		Format:     sealedFormat,
+		KDF:        "pbkdf2-sha256",
+		Iterations: sealedIterations,
+		Salt:       make([]byte, sealedSaltSize),
+	}
+	if _, err := rand.Read(s.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(passphrase, s.Salt, s.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	s.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(s.Nonce); err != nil {
+		return nil, err
+	}
+	s.Ciphertext = aead.Seal(nil, s.Nonce, plain, []byte(s.Format))
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func (s *sealed) open(passphrase string) ([]byte, error) {
+	if s.KDF != "pbkdf2-sha256" || s.Iterations <= 0 {
+		return nil, fmt.Errorf("unsupported key derivation %q with %d iterations", s.KDF, s.Iterations)
+	}
+	aead, err := newAEAD(passphrase, s.Salt, s.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := aead.Open(nil, s.Nonce, s.Ciphertext, []byte(s.Format))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+func newAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(deriveKey(passphrase, salt, iterations))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// PBKDF2-HMAC-SHA256 of RFC 8018
+func deriveKey(passphrase string, salt []byte, iterations int) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, iterations, sealedKeySize, sha256.New)
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	// RFC 7914 section 11 vectors truncated to the key size, and one
+	// cross-checked with Python hashlib.pbkdf2_hmac()
+	tests := []struct {
+		passphrase, salt string
+		iterations       int
+		want             string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56"},
+		{"secret", "0123456789abcdef", 1000, "b622961f2e0500609613c827e86b4a85ac43d8e79e0145165c54ffa7569a365f"},
+	}
+	for _, tc := range tests {
+		got := hex.EncodeToString(deriveKey(tc.passphrase, []byte(tc.salt), tc.iterations))
+		if got != tc.want {
+			t.Errorf("deriveKey(%q, %q, %d) = %s, want %s", tc.passphrase, tc.salt, tc.iterations, got, tc.want)
+		}
+	}
+}
+
+// Sealed by an earlier version, must stay readable
+func knownSealed(t *testing.T) *sealed {
+	ciphertext, err := hex.DecodeString("6eda61a3ab020fbaf2164628cc8cd1f135bf4538385c490b492dd286d2c69f8d689869e4b8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &sealed{
+		Format:     sealedFormat,
+		KDF:        "pbkdf2-sha256",
+		Iterations: 1000,
+		Salt:       []byte("0123456789abcdef"),
+		Nonce:      []byte("0123456789ab"),
+		Ciphertext: ciphertext,
+	}
+}
+
+func TestOpen(t *testing.T) {
+	const plain = `{"username":"tenuki"}`
+	tests := []struct {
+		name       string
+		modify     func(s *sealed)
+		passphrase string
+		wantErr    error // ErrWrongPassphrase or any error if errOther
+		errOther   bool
+	}{
+		{name: "known answer", modify: func(s *sealed) {}, passphrase: "secret"},
+		{name: "wrong passphrase", modify: func(s *sealed) {}, passphrase: "Secret", wantErr: ErrWrongPassphrase},
+		{name: "tampered ciphertext", modify: func(s *sealed) { s.Ciphertext[0] ^= 1 }, passphrase: "secret", wantErr: ErrWrongPassphrase},
+		{name: "tampered tag", modify: func(s *sealed) { s.Ciphertext[len(s.Ciphertext)-1] ^= 1 }, passphrase: "secret", wantErr: ErrWrongPassphrase},
+		{name: "tampered salt", modify: func(s *sealed) { s.Salt[0] ^= 1 }, passphrase: "secret", wantErr: ErrWrongPassphrase},
+		{name: "tampered nonce", modify: func(s *sealed) { s.Nonce[0] ^= 1 }, passphrase: "secret", wantErr: ErrWrongPassphrase},
+		{name: "tampered format", modify: func(s *sealed) { s.Format = "tenuki-sealed-v0" }, passphrase: "secret", wantErr: ErrWrongPassphrase},
+		{name: "tampered iterations", modify: func(s *sealed) { s.Iterations = 999 }, passphrase: "secret", wantErr: ErrWrongPassphrase},
+		{name: "short nonce", modify: func(s *sealed) { s.Nonce = s.Nonce[:8] }, passphrase: "secret", wantErr: ErrWrongPassphrase},
+		{name: "unsupported kdf", modify: func(s *sealed) { s.KDF = "scrypt" }, passphrase: "secret", errOther: true},
+		{name: "no iterations", modify: func(s *sealed) { s.Iterations = 0 }, passphrase: "secret", errOther: true},
+	}
+	for _, tc := range tests {
+		s := knownSealed(t)
+		tc.modify(s)
+		got, err := s.open(tc.passphrase)
+		switch {
+		case tc.errOther:
+			if err == nil || errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("%s: error %v, want other than %v", tc.name, err, ErrWrongPassphrase)
+			}
+		case !errors.Is(err, tc.wantErr):
+			t.Errorf("%s: error %v, want %v", tc.name, err, tc.wantErr)
+		case err == nil && string(got) != plain:
+			t.Errorf("%s: got %q, want %q", tc.name, got, plain)
+		}
+	}
+}
+
+func TestSeal(t *testing.T) {
+	plain := []byte(`{"username":"tenuki"}`)
+	data, err := seal(plain, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := parseSealed(data)
+	if s == nil {
+		t.Fatalf("parseSealed(%s) = nil", data)
+	}
+	if s.Iterations != sealedIterations || len(s.Salt) != sealedSaltSize {
+		t.Errorf("sealed with %d iterations and %d bytes salt", s.Iterations, len(s.Salt))
+	}
+	got, err := s.open("secret")
+	if err != nil || string(got) != string(plain) {
+		t.Errorf("open() = %q, %v, want %q", got, err, plain)
+	}
+	if _, err := s.open("Secret"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("open() with wrong passphrase error %v, want %v", err, ErrWrongPassphrase)
+	}
+
+	again, err := seal(plain, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s2 := parseSealed(again); string(s2.Salt) == string(s.Salt) || string(s2.Nonce) == string(s.Nonce) {
+		t.Errorf("salt or nonce reused across seals")
+	}
+}
+
+func TestReadWriteSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), Secret)
+	plain := []byte(`{"username":"tenuki"}`)
+	if err := WriteSecret(path, plain, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if encrypted, err := SecretEncrypted(path); err != nil || !encrypted {
+		t.Errorf("SecretEncrypted() = %v, %v, want true", encrypted, err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parseSealed(raw) == nil {
+		t.Errorf("secret file not sealed: %s", raw)
+	}
+
+	got, passphrase, err := ReadSecret(path, func() (string, error) { return "secret", nil })
+	if err != nil || string(got) != string(plain) || passphrase != "secret" {
+		t.Errorf("ReadSecret() = %q, %q, %v", got, passphrase, err)
+	}
+	if _, _, err := ReadSecret(path, func() (string, error) { return "Secret", nil }); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("ReadSecret() with wrong passphrase error %v, want %v", err, ErrWrongPassphrase)
+	}
+
+	if err := WriteSecret(path, plain, ""); err != nil {
+		t.Fatal(err)
+	}
+	got, passphrase, err = ReadSecret(path, func() (string, error) {
+		t.Error("passphrase asked for a plain secret")
+		return "", nil
+	})
+	if err != nil || string(got) != string(plain) || passphrase != "" {
+		t.Errorf("ReadSecret() = %q, %q, %v", got, passphrase, err)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+)
+
+const Prefs = "prefs.json"
+
+// Preferences are choices made in the app which are remembered across
+// sessions, per user.
+type Preferences struct {
+	HomeSort    string     `json:"home_sort,omitempty"`
+	HomeReverse bool       `json:"home_reverse,omitempty"`
+	HomeFilter  GameFilter `json:"home_filter"`
+	Following   []Followed `json:"following,omitempty"`
+	Automatch   Automatch  `json:"automatch"`
+
+	UIScheme string `json:"ui_scheme,omitempty"` // Chosen in the app
+
+	// Board theme chosen in the app, and ones chosen for specific games
+	BoardTheme string           `json:"board_theme,omitempty"`
+	GameThemes map[int64]string `json:"game_themes,omitempty"`
+}
+
+// Automatch preferences, rank differences are relative to own rank.
+type Automatch struct {
+	Size          string `json:"size,omitempty"`
+	Speed         string `json:"speed,omitempty"`
+	LowerRankDiff int    `json:"lower_rank_diff,omitempty"`
+	UpperRankDiff int    `json:"upper_rank_diff,omitempty"`
+	Rules         string `json:"rules,omitempty"`        // Empty for any
+	TimeControl   string `json:"time_control,omitempty"` // Empty for any
+}
+
+// Followed is a player followed on the watch page.
+type Followed struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// GameFilter narrows down a game list, zero values mean no filtering.
+type GameFilter struct {
+	MyTurn bool   `json:"my_turn,omitempty"` // Only for own games
+	Size   string `json:"size,omitempty"`    // "9x9", "13x13", "19x19" or "other"
+	Ranked string `json:"ranked,omitempty"`  // "ranked" or "unranked"
+	Speed  string `json:"speed,omitempty"`   // "blitz", "rapid", "live" or "correspondence"
+
+	// Only for watching
+	Bots    string `json:"bots,omitempty"`     // "bots" or "humans"
+	MinRank string `json:"min_rank,omitempty"` // e.g. "25k"
+	MaxRank string `json:"max_rank,omitempty"` // e.g. "5d"
+}
+
+func (s Server) PrefsPath(username string) string {
+	return filepath.Join(s.userDir(xdg.StateHome, username), Prefs)
+}
+
+// Load preferences of the user on the server, an empty one is returned if
+// never saved.
+func LoadPrefs(server Server, username string) (*Preferences, error) {
+	p := &Preferences{}
+	data, err := os.ReadFile(server.PrefsPath(username))
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return &Preferences{}, err
+	}
+	return p, nil
+}
+
+func (p *Preferences) Save(server Server, username string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := server.PrefsPath(username)
+	if err := os.MkdirAll(filepath.Dir(path), privateDirMode); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -9,25 +12,36 @@ import (
 
 const Secret = "secret.json"
 
-func SecretPath(username string) string {
-	return filepath.Join(xdg.StateHome, "tenuki", username, Secret)
+// Environment variable of the passphrase for encrypted secret files, new
+// logins are saved encrypted when it is set.
+const PassphraseEnv = "TENUKI_PASSPHRASE"
+
+// Per user state holds OAuth tokens, keep it away from other users
+const (
+	privateDirMode  fs.FileMode = 0700
+	privateFileMode fs.FileMode = 0600
+)
+
+func (s Server) SecretPath(username string) string {
+	return filepath.Join(s.userDir(xdg.StateHome, username), Secret)
 }
 
-// Return the matching $XDG_STATE_HOME/tenuki/<username|*>/secret.json files
-func SearchSecrets(username string) []string {
+// Return the matching $XDG_STATE_HOME/tenuki/<username|*>/secret.json files,
+// of the server
+func (s Server) SearchSecrets(username string) []string {
 	if username != "" {
-		p := SecretPath(username)
-		if _, err := os.Stat(SecretPath(username)); err == nil {
+		p := s.SecretPath(username)
+		if _, err := os.Stat(p); err == nil {
 			return []string{p}
 		}
 		return nil
 	}
 
 	var res []string
-	entries, _ := os.ReadDir(filepath.Join(xdg.StateHome, "tenuki"))
+	entries, _ := os.ReadDir(s.dir(xdg.StateHome))
 	for _, entry := range entries {
 		if entry.IsDir() {
-			p := SecretPath(entry.Name())
+			p := s.SecretPath(entry.Name())
 			if _, err := os.Stat(p); err == nil {
 				res = append(res, p)
 			}
@@ -35,3 +49,100 @@ func SearchSecrets(username string) []string {
 	}
 	return res
 }
+
+// Usernames of the saved secret files, sorted
+func (s Server) SavedUsernames() []string {
+	var res []string
+	for _, p := range s.SearchSecrets("") {
+		res = append(res, filepath.Base(filepath.Dir(p)))
+	}
+	return res
+}
+
+// Whether the secret file is encrypted with a passphrase.
+func SecretEncrypted(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return parseSealed(data) != nil, nil
+}
+
+// ReadSecret returns the plain JSON of the secret file. For an encrypted file
+// the passphrase is asked from getPassphrase and returned, otherwise the
+// returned passphrase is empty. Loose permissions left by older versions are
+// tightened on the way.
+func ReadSecret(path string, getPassphrase func() (string, error)) ([]byte, string, error) {
+	if err := restrictSecret(path); err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	s := parseSealed(data)
+	if s == nil {
+		return data, "", nil
+	}
+	passphrase, err := getPassphrase()
+	if err != nil {
+		return nil, "", err
+	}
+	plain, err := s.open(passphrase)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	return plain, passphrase, nil
+}
+
+// WriteSecret saves the plain JSON data to the secret file, encrypted when
+// passphrase is not empty. The file is replaced atomically so that an
+// interrupted write never loses the tokens.
+func WriteSecret(path string, data []byte, passphrase string) error {
+	if passphrase != "" {
+		var err error
+		if data, err = seal(data, passphrase); err != nil {
+			return err
+		}
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, privateDirMode); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "."+Secret+".*") // Created with 0600
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // No-op once renamed
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Enforce 0600 on the secret file and 0700 on its directory, which used to be
+// created with 0770.
+func restrictSecret(path string) error {
+	for p, mode := range map[string]fs.FileMode{
+		filepath.Dir(path): privateDirMode,
+		path:               privateFileMode,
+	} {
+		info, err := os.Stat(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&^mode != 0 {
+			if err := os.Chmod(p, mode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
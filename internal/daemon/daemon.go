@@ -0,0 +1,280 @@
+// Package daemon runs headless, watching own games and running user configured
+// hook commands on events.
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+// Events
+const (
+	EventMyTurn       = "my_turn"
+	EventGameFinished = "game_finished"
+	EventChat         = "chat"
+	EventClockLow     = "clock_low"
+)
+
+const (
+	pollInterval = time.Minute
+	hookTimeout  = 30 * time.Second
+)
+
+// Payload is written to hook commands as JSON on stdin.
+type Payload struct {
+	Event      string `json:"event"`
+	GameID     int64  `json:"game_id"`
+	GameName   string `json:"game_name"`
+	URL        string `json:"url"`
+	Opponent   string `json:"opponent,omitempty"`
+	MoveNumber int    `json:"move_number"`
+	Clock      string `json:"clock,omitempty"`   // Clock of the player to move, for clock_low
+	Outcome    string `json:"outcome,omitempty"` // For game_finished
+	Chat       *Chat  `json:"chat,omitempty"`
+}
+
+type Chat struct {
+	From string `json:"from"`
+	Body string `json:"body"`
+}
+
+type daemon struct {
+	// Updated from the socket, accessed atomically. First in the struct for
+	// 64-bit alignment on 32-bit platforms.
+	pingDrift int64
+	pingLag   int64
+
+	client   *googs.Client
+	hooks    map[string][]string
+	clockLow time.Duration
+	started  time.Time
+
+	lock     sync.Mutex
+	games    map[int64]*googs.Game // Active games, chats subscribed
+	notified map[string]bool       // Key is event/gameID/move number
+	finished map[int64]bool
+}
+
+// Run watches own games until interrupted.
+func Run(client *googs.Client, cfg *config.Config) error {
+	d := &daemon{
+		client: client,
+		hooks: map[string][]string{
+			EventMyTurn:       cfg.Hooks.MyTurn,
+			EventGameFinished: cfg.Hooks.GameFinished,
+			EventChat:         cfg.Hooks.Chat,
+			EventClockLow:     cfg.Hooks.ClockLow,
+		},
+		clockLow: cfg.Hooks.ClockLowThreshold.Std(),
+		started:  time.Now(),
+		games:    make(map[int64]*googs.Game),
+		notified: make(map[string]bool),
+		finished: make(map[int64]bool),
+	}
+	log.Printf("Daemon started as %s, config %s", client.Username, config.ConfigPath())
+
+	client.OnNetPong(func(drift, latency int64) {
+		atomic.StoreInt64(&d.pingDrift, drift)
+		atomic.StoreInt64(&d.pingLag, latency)
+	})
+	client.OnActiveGame(d.onActiveGame)
+	if err := d.poll(); err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	pingTicker := time.NewTicker(10 * time.Second)
+	pollTicker := time.NewTicker(pollInterval)
+	defer pingTicker.Stop()
+	defer pollTicker.Stop()
+	for {
+		select {
+		case <-pingTicker.C:
+			client.NetPing(atomic.LoadInt64(&d.pingDrift), atomic.LoadInt64(&d.pingLag))
+		case <-pollTicker.C:
+			if err := d.poll(); err != nil {
+				log.Printf("Poll active games %v", err)
+			}
+		case sig := <-signals:
+			log.Printf("Daemon stopped by %s", sig)
+			return nil
+		}
+	}
+}
+
+// Poll active games for new games, turns and clocks. Events from the socket
+// may be missed on reconnection.
+func (d *daemon) poll() error {
+	ov, err := ogs.Overview(d.client)
+	if err != nil {
+		return err
+	}
+
+	active := make(map[int64]bool)
+	for i := range ov.ActiveGames {
+		g := &ov.ActiveGames[i].Game
+		active[g.GameID] = true
+		d.watch(g)
+		if !g.IsMyTurn(d.client.UserID) {
+			continue
+		}
+		d.fire(EventMyTurn, g, len(g.Moves), nil)
+		if left := ogs.GameTimeLeft(g); left < d.clockLow {
+			turn := util.Cond(g.Clock.CurrentPlayerID == g.Players.Black.ID, googs.PlayerBlack, googs.PlayerWhite)
+			d.fire(EventClockLow, g, len(g.Moves), func(p *Payload) {
+				p.Clock = g.Clock.ComputeClock(&g.TimeControl, turn).String()
+			})
+		}
+	}
+
+	// Games gone from the active list are finished
+	d.lock.Lock()
+	var gone []*googs.Game
+	for id, g := range d.games {
+		if !active[id] {
+			gone = append(gone, g)
+			delete(d.games, id)
+		}
+	}
+	d.lock.Unlock()
+	for _, g := range gone {
+		d.gameFinished(g.GameID)
+	}
+	return nil
+}
+
+// Subscribe chats of the game once
+func (d *daemon) watch(g *googs.Game) {
+	d.lock.Lock()
+	_, ok := d.games[g.GameID]
+	d.games[g.GameID] = g
+	d.lock.Unlock()
+	if ok {
+		return
+	}
+
+	gameID := g.GameID
+	d.client.OnGameChat(gameID, func(chat *googs.GameChat) {
+		line := chat.Line
+		// Skip own and chat history sent upon connection
+		if line.PlayerID == d.client.UserID || line.Date.Before(d.started) {
+			return
+		}
+		d.lock.Lock()
+		game := d.games[gameID]
+		d.lock.Unlock()
+		if game == nil {
+			return
+		}
+		d.fire(EventChat, game, line.MoveNumber, func(p *Payload) {
+			p.Chat = &Chat{From: line.Username, Body: line.Body}
+		})
+	})
+	if err := d.client.GameConnect(gameID); err != nil {
+		log.Printf("Connect game %d %v", gameID, err)
+	}
+}
+
+func (d *daemon) onActiveGame(e *googs.GameListEntry) {
+	switch e.Phase {
+	case googs.FinishedPhase:
+		d.gameFinished(e.ID)
+	case googs.PlayPhase:
+		if e.PlayerToMove != d.client.UserID {
+			return
+		}
+		// Fired by polling, so that my_turn is deduplicated on the same
+		// move count whichever noticed first. New games are picked up too.
+		if err := d.poll(); err != nil {
+			log.Printf("Poll active games %v", err)
+		}
+	}
+}
+
+func (d *daemon) gameFinished(gameID int64) {
+	d.lock.Lock()
+	if d.finished[gameID] {
+		d.lock.Unlock()
+		return
+	}
+	d.finished[gameID] = true
+	delete(d.games, gameID)
+	d.lock.Unlock()
+
+	d.client.GameDisconnect(gameID)
+	g, err := ogs.Game(d.client, gameID)
+	if err != nil {
+		log.Printf("Load finished game %d %v", gameID, err)
+		return
+	}
+	d.fire(EventGameFinished, g, len(g.Moves), func(p *Payload) {
+		p.Outcome = g.Result()
+	})
+}
+
+// Run hooks of the event once per game and move number (except chats),
+// returns false when already fired.
+func (d *daemon) fire(event string, g *googs.Game, moveNumber int, customize func(*Payload)) bool {
+	if event != EventChat {
+		key := fmt.Sprintf("%s/%d/%d", event, g.GameID, moveNumber)
+		d.lock.Lock()
+		seen := d.notified[key]
+		d.notified[key] = true
+		d.lock.Unlock()
+		if seen {
+			return false
+		}
+	}
+
+	p := &Payload{
+		Event:      event,
+		GameID:     g.GameID,
+		GameName:   g.GameName,
+		URL:        ogs.GameURL(d.client, g.GameID),
+		Opponent:   g.Opponent(d.client.UserID).String(),
+		MoveNumber: moveNumber,
+	}
+	if customize != nil {
+		customize(p)
+	}
+	log.Printf("Event %s of game %d at move %d", event, g.GameID, moveNumber)
+	for _, cmd := range d.hooks[event] {
+		go runHook(cmd, p)
+	}
+	return true
+}
+
+// Run the command with sh, payload is written to stdin
+func runHook(cmd string, p *Payload) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		log.Printf("Encode payload %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	c.Stdin = bytes.NewReader(data)
+	c.Env = append(os.Environ(), "TENUKI_EVENT="+p.Event, fmt.Sprintf("TENUKI_GAME_ID=%d", p.GameID))
+	if out, err := c.CombinedOutput(); err != nil {
+		log.Printf("Hook %q failed: %v: %s", cmd, err, out)
+	}
+}
@@ -0,0 +1,34 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/ymattw/googs"
+)
+
+func TestFireDedup(t *testing.T) {
+	d := &daemon{client: googs.NewClient("id", "secret"), notified: map[string]bool{}}
+	g1, g2 := &googs.Game{GameID: 1}, &googs.Game{GameID: 2}
+	tests := []struct {
+		event      string
+		g          *googs.Game
+		moveNumber int
+		want       bool
+	}{
+		{EventMyTurn, g1, 10, true},
+		{EventMyTurn, g1, 10, false},
+		{EventMyTurn, g1, 12, true},
+		{EventMyTurn, g2, 10, true},
+		{EventClockLow, g1, 10, true},
+		{EventClockLow, g1, 10, false},
+		{EventGameFinished, g1, 12, true},
+		{EventGameFinished, g1, 12, false},
+		{EventChat, g1, 12, true},
+		{EventChat, g1, 12, true},
+	}
+	for i, tc := range tests {
+		if got := d.fire(tc.event, tc.g, tc.moveNumber, nil); got != tc.want {
+			t.Errorf("#%d fire(%s, %d, %d) = %v, want %v", i, tc.event, tc.g.GameID, tc.moveNumber, got, tc.want)
+		}
+	}
+}
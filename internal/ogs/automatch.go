@@ -0,0 +1,63 @@
+package ogs
+
+import "fmt"
+
+// AutomatchRequest describes the match wanted, rank differences are relative
+// to own rank.
+type AutomatchRequest struct {
+	UUID          string
+	Size          string // "9x9", "13x13" or "19x19"
+	Speed         string // "blitz", "rapid", "live" or "correspondence"
+	LowerRankDiff int
+	UpperRankDiff int
+	Rules         string // e.g. "japanese", empty for no preference
+	TimeControl   string // e.g. "byoyomi", empty for no preference
+}
+
+// FindMatch starts searching for a match, call OnMatchStart first to be told
+// when found.
+func (s *Socket) FindMatch(req *AutomatchRequest) error {
+	return s.conn.Emit("automatch/find_match", findMatchMessage(req))
+}
+
+func findMatchMessage(req *AutomatchRequest) map[string]any {
+	system := func(s string) map[string]any { return map[string]any{"system": s} }
+	return map[string]any{
+		"uuid": req.UUID,
+		"size_speed_options": []map[string]any{
+			{"size": req.Size, "speed": req.Speed},
+		},
+		"lower_rank_diff": req.LowerRankDiff,
+		"upper_rank_diff": req.UpperRankDiff,
+		"rules":           condition(req.Rules, req.Rules, "japanese"),
+		"time_control":    condition(req.TimeControl, system(req.TimeControl), system("byoyomi")),
+		"handicap":        condition("", nil, "enabled"),
+	}
+}
+
+// Required value when given is set, otherwise no preference with the usual
+// default
+func condition(given string, value, fallback any) map[string]any {
+	if given == "" {
+		return map[string]any{"condition": "no-preference", "value": fallback}
+	}
+	return map[string]any{"condition": "required", "value": value}
+}
+
+// CancelMatch cancels a search started by FindMatch.
+func (s *Socket) CancelMatch(uuid string) error {
+	return s.conn.Emit("automatch/cancel", map[string]any{"uuid": uuid})
+}
+
+// OnMatchStart starts watching found matches.
+func (s *Socket) OnMatchStart(fn func(uuid string, gameID int64)) error {
+	type start struct {
+		UUID   string
+		GameID int64 `json:"game_id"`
+	}
+	callback := func(_ any, m start) { fn(m.UUID, m.GameID) }
+	if err := s.conn.On("automatch/start", callback); err != nil {
+		return fmt.Errorf("watch automatch %w", err)
+	}
+	return nil
+}
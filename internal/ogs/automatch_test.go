@@ -0,0 +1,40 @@
+package ogs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindMatchMessage(t *testing.T) {
+	tests := []struct {
+		name            string
+		req             AutomatchRequest
+		rules, timeCtrl map[string]any
+	}{
+		{
+			name:     "any",
+			req:      AutomatchRequest{Size: "19x19", Speed: "live"},
+			rules:    map[string]any{"condition": "no-preference", "value": "japanese"},
+			timeCtrl: map[string]any{"condition": "no-preference", "value": map[string]any{"system": "byoyomi"}},
+		},
+		{
+			name:     "required",
+			req:      AutomatchRequest{Size: "9x9", Speed: "blitz", Rules: "chinese", TimeControl: "fischer"},
+			rules:    map[string]any{"condition": "required", "value": "chinese"},
+			timeCtrl: map[string]any{"condition": "required", "value": map[string]any{"system": "fischer"}},
+		},
+	}
+	for _, tc := range tests {
+		m := findMatchMessage(&tc.req)
+		if !reflect.DeepEqual(m["rules"], tc.rules) {
+			t.Errorf("%s: rules %v, want %v", tc.name, m["rules"], tc.rules)
+		}
+		if !reflect.DeepEqual(m["time_control"], tc.timeCtrl) {
+			t.Errorf("%s: time_control %v, want %v", tc.name, m["time_control"], tc.timeCtrl)
+		}
+		wantSizeSpeed := []map[string]any{{"size": tc.req.Size, "speed": tc.req.Speed}}
+		if !reflect.DeepEqual(m["size_speed_options"], wantSizeSpeed) {
+			t.Errorf("%s: size_speed_options %v, want %v", tc.name, m["size_speed_options"], wantSizeSpeed)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ogs
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ymattw/googs"
+)
+
+// ChallengeRequest describes a challenge to create.
+type ChallengeRequest struct {
+	Initialized     bool          `json:"initialized"`
+	MinRanking      int           `json:"min_ranking"`
+	MaxRanking      int           `json:"max_ranking"`
+	ChallengerColor string        `json:"challenger_color"` // "automatic", "black" or "white"
+	Game            ChallengeGame `json:"game"`
+}
+
+type ChallengeGame struct {
+	Name                  string         `json:"name"`
+	Rules                 string         `json:"rules"`
+	Ranked                bool           `json:"ranked"`
+	Private               bool           `json:"private"`
+	Width                 int            `json:"width"`
+	Height                int            `json:"height"`
+	Handicap              int            `json:"handicap"`  // -1 for automatic
+	KomiAuto              string         `json:"komi_auto"` // "automatic" or "custom"
+	Komi                  *float32       `json:"komi"`      // Only for custom
+	DisableAnalysis       bool           `json:"disable_analysis"`
+	PauseOnWeekends       bool           `json:"pause_on_weekends"`
+	TimeControl           string         `json:"time_control"`
+	TimeControlParameters map[string]any `json:"time_control_parameters"`
+}
+
+// Challenge is a pending challenge sent or received.
+type Challenge struct {
+	ID              int64
+	Challenger      Player
+	Challenged      *Player // Nil for open challenges
+	ChallengerColor string  `json:"challenger_color"`
+	Created         time.Time
+	Game            struct {
+		ID          int64
+		Name        string
+		Rules       string
+		Ranked      bool
+		Private     bool
+		Width       int
+		Height      int
+		Handicap    int
+		Komi        *float32
+		TimeControl string `json:"time_control"`
+	}
+}
+
+// CreateChallenge creates an open challenge when playerID is 0, or challenges
+// the given player. IDs of the challenge and the game are returned.
+func CreateChallenge(c *googs.Client, playerID int64, req *ChallengeRequest) (int64, int64, error) {
+	uri := "/api/v1/challenges"
+	if playerID != 0 {
+		uri = fmt.Sprintf("/api/v1/players/%d/challenge", playerID)
+	}
+	res := struct {
+		Challenge int64
+		Game      int64
+	}{}
+	if err := send(c, "POST", uri, req, &res); err != nil {
+		return 0, 0, err
+	}
+	return res.Challenge, res.Game, nil
+}
+
+// MyChallenges returns pending challenges sent or received.
+func MyChallenges(c *googs.Client) ([]Challenge, error) {
+	params := url.Values{}
+	params.Set("page_size", "100")
+	res := struct {
+		Results []Challenge
+	}{}
+	if err := get(c, "/api/v1/me/challenges", params, &res); err != nil {
+		return nil, err
+	}
+	return res.Results, nil
+}
+
+// AcceptChallenge accepts a challenge received, ID of the game is returned
+// when the server tells.
+func AcceptChallenge(c *googs.Client, challengeID int64) (int64, error) {
+	return accept(c, fmt.Sprintf("/api/v1/me/challenges/%d/accept", challengeID))
+}
+
+// AcceptOpenChallenge accepts an open challenge, e.g. from the seek graph.
+func AcceptOpenChallenge(c *googs.Client, challengeID int64) (int64, error) {
+	return accept(c, fmt.Sprintf("/api/v1/challenges/%d/accept", challengeID))
+}
+
+func accept(c *googs.Client, uri string) (int64, error) {
+	res := struct {
+		Game any // Not always a number
+	}{}
+	if err := send(c, "POST", uri, struct{}{}, &res); err != nil {
+		return 0, err
+	}
+	if id, ok := res.Game.(float64); ok {
+		return int64(id), nil
+	}
+	return 0, nil
+}
+
+// CancelChallenge cancels a challenge sent, or declines one received.
+func CancelChallenge(c *googs.Client, challengeID int64) error {
+	return send(c, "DELETE", fmt.Sprintf("/api/v1/me/challenges/%d", challengeID), nil, nil)
+}
@@ -0,0 +1,118 @@
+package ogs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+)
+
+// LoadClient loads a client from the secret file, getPassphrase is asked only
+// if the file is encrypted, and the passphrase is returned then. A client not
+// logged in but with maybe-set client ID/secret is returned if the saved login
+// no longer works. Same as googs.LoadClient() but in memory, so the decrypted
+// secret never touches the disk.
+func LoadClient(server config.Server, path string, getPassphrase func() (string, error)) (*googs.Client, string, error) {
+	data, passphrase, err := config.ReadSecret(path, getPassphrase)
+	if err != nil {
+		return nil, "", err
+	}
+	var c googs.Client
+	c.BaseURL, c.RealtimeURL = server.REST(), server.Realtime()
+	if err := json.Unmarshal(data, &c); err != nil {
+		return NewClient(server, c.ClientID, c.ClientSecret), passphrase, nil
+	}
+
+	// OGS access token is valid for 30 days, refresh if it's expiring in 7
+	// days.
+	if time.Now().Add(7*24*time.Hour).After(c.ExpiresAt) || identify(&c) != nil {
+		if err := RefreshToken(&c); err != nil {
+			return NewClient(server, c.ClientID, c.ClientSecret), passphrase, nil
+		}
+		if err := SaveClient(path, &c, passphrase); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := identify(&c); err != nil {
+		return NewClient(server, c.ClientID, c.ClientSecret), passphrase, nil
+	}
+	if err := c.Connect(); err != nil {
+		return NewClient(server, c.ClientID, c.ClientSecret), passphrase, nil
+	}
+	return &c, passphrase, nil
+}
+
+// SaveClient writes the client to the secret file, encrypted when passphrase
+// is not empty.
+func SaveClient(path string, c *googs.Client, passphrase string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return config.WriteSecret(path, data, passphrase)
+}
+
+// Login authenticates with username and password then connects, same as
+// googs.Client.Login() but through our REST requests.
+func Login(c *googs.Client, username, password string) error {
+	if err := authenticate(c, url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+	}); err != nil {
+		return err
+	}
+	if err := identify(c); err != nil {
+		return err
+	}
+	return c.Connect()
+}
+
+// RefreshToken exchanges the refresh token for a new access token regardless
+// of its expiry, SaveClient() is expected to persist it.
+func RefreshToken(c *googs.Client) error {
+	if c.RefreshToken == "" {
+		return errors.New("no refresh token, login needed")
+	}
+	return authenticate(c, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {c.RefreshToken},
+	})
+}
+
+// Request tokens with the OAuth grant in data, then the auth config.
+func authenticate(c *googs.Client, data url.Values) error {
+	data.Set("client_id", c.ClientID)
+	data.Set("client_secret", c.ClientSecret)
+	if err := postForm(c, "/oauth2/token/", data, &c.Token); err != nil {
+		return fmt.Errorf("request token: %w", err)
+	}
+	c.ExpiresAt = time.Now().Add(time.Duration(c.ExpiresIn) * time.Second)
+	c.ExpiresIn = 0 // Unset to omit when persisting
+	if err := get(c, "/api/v1/ui/config/", nil, &c.Auth); err != nil {
+		return fmt.Errorf("request auth config: %w", err)
+	}
+	return nil
+}
+
+// Verify the access token and fill in Username and UserID.
+func identify(c *googs.Client) error {
+	me := googs.User{}
+	if err := get(c, "/api/v1/me", nil, &me); err != nil {
+		return err
+	}
+	c.Username, c.UserID = me.Username, me.ID
+	return nil
+}
+
+// IsAuthError tells whether the server rejected the access token, only the
+// HTTP status is reported in error messages.
+func IsAuthError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "401 Unauthorized")
+}
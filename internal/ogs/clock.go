@@ -0,0 +1,42 @@
+package ogs
+
+import (
+	"math"
+	"time"
+
+	"github.com/ymattw/googs"
+)
+
+// TimeLeft returns the total time left on a computed clock including all
+// remaining periods, practically infinite for games without a clock.
+func TimeLeft(c *googs.ComputedClock, tc *googs.TimeControl) time.Duration {
+	if c == nil {
+		return time.Duration(math.MaxInt64)
+	}
+	seconds := c.MainTime
+	switch c.System {
+	case googs.ClockByoyomi:
+		periods := c.PeriodsLeft - 1
+		if periods < 0 {
+			periods = 0
+		}
+		seconds = c.MainTime + c.PeriodTimeLeft + float64(periods)*tc.PeriodTime
+	case googs.ClockCanadian:
+		seconds = c.MainTime + c.BlockTimeLeft
+	case googs.ClockNone, googs.ClockUnknown:
+		seconds = math.Inf(1)
+	}
+	if seconds > math.MaxInt64/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// GameTimeLeft returns TimeLeft of the player to move in the game.
+func GameTimeLeft(g *googs.Game) time.Duration {
+	turn := googs.PlayerWhite
+	if g.Clock.CurrentPlayerID == g.Players.Black.ID {
+		turn = googs.PlayerBlack
+	}
+	return TimeLeft(g.Clock.ComputeClock(&g.TimeControl, turn), &g.TimeControl)
+}
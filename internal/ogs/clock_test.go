@@ -0,0 +1,53 @@
+package ogs
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ymattw/googs"
+)
+
+func TestTimeLeft(t *testing.T) {
+	forever := time.Duration(math.MaxInt64)
+	tests := []struct {
+		name  string
+		clock *googs.ComputedClock
+		tc    googs.TimeControl
+		want  time.Duration
+	}{
+		{"nil clock", nil, googs.TimeControl{}, forever},
+		{"no clock", &googs.ComputedClock{System: googs.ClockNone}, googs.TimeControl{}, forever},
+		{"absolute", &googs.ComputedClock{System: googs.ClockAbsolute, MainTime: 90}, googs.TimeControl{}, 90 * time.Second},
+		{"fischer", &googs.ComputedClock{System: googs.ClockFischer, MainTime: 1.5}, googs.TimeControl{}, 1500 * time.Millisecond},
+		{
+			"byoyomi in main time",
+			&googs.ComputedClock{System: googs.ClockByoyomi, MainTime: 600, PeriodsLeft: 5, PeriodTimeLeft: 30},
+			googs.TimeControl{PeriodTime: 30},
+			(600 + 30 + 4*30) * time.Second,
+		},
+		{
+			"byoyomi last period",
+			&googs.ComputedClock{System: googs.ClockByoyomi, PeriodsLeft: 1, PeriodTimeLeft: 12},
+			googs.TimeControl{PeriodTime: 30},
+			12 * time.Second,
+		},
+		{
+			"byoyomi timed out",
+			&googs.ComputedClock{System: googs.ClockByoyomi, PeriodsLeft: 0},
+			googs.TimeControl{PeriodTime: 30},
+			0,
+		},
+		{
+			"canadian",
+			&googs.ComputedClock{System: googs.ClockCanadian, MainTime: 60, BlockTimeLeft: 300},
+			googs.TimeControl{},
+			360 * time.Second,
+		},
+	}
+	for _, tc := range tests {
+		if got := TimeLeft(tc.clock, &tc.tc); got != tc.want {
+			t.Errorf("%s: TimeLeft() = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
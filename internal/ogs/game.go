@@ -0,0 +1,50 @@
+package ogs
+
+import (
+	"fmt"
+
+	"github.com/ymattw/googs"
+)
+
+// Overview returns active games, same as googs.Client.Overview() but from the
+// server in use.
+func Overview(c *googs.Client) (*googs.Overview, error) {
+	res := googs.Overview{}
+	if err := get(c, "/api/v1/ui/overview", nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Game fetches general game information, mostly static. Same as
+// googs.Client.Game() but from the server in use.
+func Game(c *googs.Client, gameID int64) (*googs.Game, error) {
+	// /termination-api/game/:ID does not work for private games
+	res := struct {
+		Game googs.Game `json:"gamedata"`
+	}{}
+	if err := get(c, fmt.Sprintf("/api/v1/games/%d", gameID), nil, &res); err != nil {
+		return nil, err
+	}
+	g := &res.Game
+	if g.Height <= 0 || g.Width <= 0 || g.Height != g.Width {
+		return nil, fmt.Errorf("invalid board dimension %d x %d", g.Width, g.Height)
+	}
+	return g, nil
+}
+
+// GameState fetches current game information with board snapshot. Same as
+// googs.Client.GameState() but from the server in use.
+func GameState(c *googs.Client, gameID int64) (*googs.GameState, error) {
+	res := googs.GameState{}
+	if err := get(c, fmt.Sprintf("/termination-api/game/%d/state", gameID), nil, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Board) == 0 || len(res.Board[0]) == 0 {
+		return nil, fmt.Errorf("invalid empty board")
+	}
+	if len(res.Board) != len(res.Board[0]) || len(res.Board) > 25 {
+		return nil, fmt.Errorf("invalid board dimension %d x %d", len(res.Board), len(res.Board[0]))
+	}
+	return &res, nil
+}
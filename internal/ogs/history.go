@@ -0,0 +1,78 @@
+// Package ogs implements OGS APIs which are not (yet) covered by googs, on top
+// of an authenticated googs.Client.
+package ogs
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ymattw/googs"
+)
+
+// HistoryGame is a finished game as listed in a player's game history.
+type HistoryGame struct {
+	ID        int64
+	Name      string
+	Width     int
+	Height    int
+	Ranked    bool
+	Handicap  int
+	Outcome   string
+	Annulled  bool
+	BlackLost bool `json:"black_lost"`
+	WhiteLost bool `json:"white_lost"`
+	Started   time.Time
+	Ended     time.Time
+	Players   struct {
+		Black Player
+		White Player
+	}
+}
+
+func (g *HistoryGame) Opponent(myUserID int64) googs.Player {
+	if g.Players.Black.ID == myUserID {
+		return g.Players.White.Player()
+	}
+	return g.Players.Black.Player()
+}
+
+// Result returns the game result from the given player's perspective.
+func (g *HistoryGame) Result(myUserID int64) string {
+	if g.Annulled {
+		return "Annulled"
+	}
+	lost := g.WhiteLost
+	if g.Players.Black.ID == myUserID {
+		lost = g.BlackLost
+	}
+	if g.BlackLost == g.WhiteLost {
+		return "Tie " + g.Outcome
+	}
+	if lost {
+		return "Lost by " + g.Outcome
+	}
+	return "Won by " + g.Outcome
+}
+
+// GameHistory is a page of finished games.
+type GameHistory struct {
+	Count   int // Total number of games
+	Results []HistoryGame
+}
+
+// FinishedGames returns the given page (1 based) of the player's finished
+// games, most recent first.
+func FinishedGames(c *googs.Client, playerID int64, page, pageSize int) (*GameHistory, error) {
+	params := url.Values{}
+	params.Set("ended__isnull", "false")
+	params.Set("ordering", "-ended")
+	params.Set("page", fmt.Sprintf("%d", page))
+	params.Set("page_size", fmt.Sprintf("%d", pageSize))
+
+	res := GameHistory{}
+	if err := get(c, fmt.Sprintf("/api/v1/players/%d/games/", playerID), params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,162 @@
+package ogs
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ymattw/googs"
+)
+
+// Authorization is a pending OAuth authorization code flow with PKCE (RFC
+// 7636). The user approves in a browser, which OGS redirects to a listener on
+// localhost with the code, so the password is never typed in the terminal.
+type Authorization struct {
+	URL string // To open in a browser
+
+	client      *googs.Client // Of the client ID and server, left as is
+	redirectURI string
+	verifier    string
+	state       string
+	server      *http.Server
+	result      chan authResult
+	closed      chan struct{}
+	closeOnce   sync.Once
+}
+
+type authResult struct {
+	code string
+	err  error
+}
+
+// StartAuthorization listens on the redirect URI, which must be a loopback
+// http URL registered with the OGS application, e.g.
+// "http://127.0.0.1:8765/callback". Wait() returns a new client logged in
+// with the ID and server of c.
+func StartAuthorization(c *googs.Client, redirectURI string) (*Authorization, error) {
+	if c.ClientID == "" {
+		return nil, errors.New("client ID is required")
+	}
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("tcp", u.Host)
+	if err != nil {
+		return nil, fmt.Errorf("listen on redirect URI: %w", err)
+	}
+
+	a := &Authorization{
+		client:      c,
+		redirectURI: redirectURI,
+		verifier:    randomString(32),
+		state:       randomString(16),
+		result:      make(chan authResult, 1),
+		closed:      make(chan struct{}),
+	}
+	a.URL = c.BaseURL + "/oauth2/authorize/?" + url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.ClientID},
+		"redirect_uri":          {redirectURI},
+		"state":                 {a.state},
+		"code_challenge":        {pkceChallenge(a.verifier)},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(u.Path, a.callback)
+	a.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go a.server.Serve(l)
+	return a, nil
+}
+
+func (a *Authorization) callback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var res authResult
+	switch {
+	case q.Get("state") != a.state:
+		http.Error(w, "State mismatch, please start over from tenuki.", http.StatusBadRequest)
+		return // Not ours, keep waiting
+	case q.Get("error") != "":
+		res.err = fmt.Errorf("authorization %s %s", q.Get("error"), q.Get("error_description"))
+	case q.Get("code") == "":
+		res.err = errors.New("authorization code missing in redirect")
+	default:
+		res.code = q.Get("code")
+	}
+	if res.err != nil {
+		http.Error(w, res.err.Error(), http.StatusBadRequest)
+	} else {
+		fmt.Fprintln(w, "Tenuki is authorized, you can close this page.")
+	}
+	select {
+	case a.result <- res:
+	default: // Already got one
+	}
+}
+
+// Wait for the redirect then exchange the code for a logged in client.
+func (a *Authorization) Wait(timeout time.Duration) (*googs.Client, error) {
+	defer a.Close()
+	var res authResult
+	select {
+	case res = <-a.result:
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("not authorized in %s", timeout)
+	case <-a.closed:
+		return nil, ErrAuthorizationCanceled
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	return a.exchange(res.code)
+}
+
+var ErrAuthorizationCanceled = errors.New("authorization canceled")
+
+// Stop listening, a pending Wait() fails.
+func (a *Authorization) Close() {
+	a.closeOnce.Do(func() {
+		close(a.closed)
+		a.server.Close()
+	})
+}
+
+func (a *Authorization) exchange(code string) (*googs.Client, error) {
+	c := googs.NewClient(a.client.ClientID, a.client.ClientSecret)
+	c.BaseURL, c.RealtimeURL = a.client.BaseURL, a.client.RealtimeURL
+	if err := authenticate(c, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {a.redirectURI},
+		"code_verifier": {a.verifier},
+	}); err != nil {
+		return nil, err
+	}
+	if err := identify(c); err != nil {
+		return nil, err
+	}
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// S256 code challenge of RFC 7636
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
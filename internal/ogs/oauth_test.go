@@ -0,0 +1,91 @@
+package ogs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/ymattw/tenuki/internal/config"
+)
+
+func TestPKCEChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if got := pkceChallenge(verifier); got != want {
+		t.Errorf("pkceChallenge(%q) = %q, want %q", verifier, got, want)
+	}
+}
+
+func TestRandomString(t *testing.T) {
+	// RFC 7636 section 4.1: 43 to 128 unreserved characters
+	valid := regexp.MustCompile(`^[A-Za-z0-9._~-]{43,128}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 10; i++ {
+		s := randomString(32)
+		if !valid.MatchString(s) {
+			t.Errorf("randomString(32) = %q, not a valid code verifier", s)
+		}
+		if seen[s] {
+			t.Errorf("randomString(32) repeated %q", s)
+		}
+		seen[s] = true
+	}
+}
+
+func TestStartAuthorization(t *testing.T) {
+	c := NewClient(config.Server{Host: config.DefaultServer}, "id", "secret")
+	a, err := StartAuthorization(c, "http://127.0.0.1:0/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	u, err := url.Parse(a.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != config.DefaultServer {
+		t.Errorf("authorize URL %q not on the server of the client", a.URL)
+	}
+	q := u.Query()
+	if got := q.Get("code_challenge"); got != pkceChallenge(a.verifier) {
+		t.Errorf("code_challenge %q, want %q", got, pkceChallenge(a.verifier))
+	}
+	if got := q.Get("code_challenge_method"); got != "S256" {
+		t.Errorf("code_challenge_method %q, want S256", got)
+	}
+	if got := q.Get("state"); got != a.state {
+		t.Errorf("state %q, want %q", got, a.state)
+	}
+
+	tests := []struct {
+		name       string
+		query      url.Values
+		wantStatus int
+		wantCode   string
+		wantErr    bool
+	}{
+		{"state mismatch", url.Values{"state": {"other"}, "code": {"c"}}, http.StatusBadRequest, "", false},
+		{"code", url.Values{"state": {a.state}, "code": {"c"}}, http.StatusOK, "c", false},
+	}
+	for _, tc := range tests {
+		w := httptest.NewRecorder()
+		a.callback(w, httptest.NewRequest("GET", "/callback?"+tc.query.Encode(), nil))
+		if w.Code != tc.wantStatus {
+			t.Errorf("%s: status %d, want %d", tc.name, w.Code, tc.wantStatus)
+		}
+		select {
+		case res := <-a.result:
+			if res.code != tc.wantCode || (res.err != nil) != tc.wantErr {
+				t.Errorf("%s: result %q, %v", tc.name, res.code, res.err)
+			}
+		default:
+			if tc.wantCode != "" || tc.wantErr {
+				t.Errorf("%s: no result", tc.name)
+			}
+		}
+	}
+}
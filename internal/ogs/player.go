@@ -0,0 +1,54 @@
+package ogs
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ymattw/googs"
+)
+
+// Player is the player summary returned by OGS player APIs.
+type Player struct {
+	ID           int64
+	Username     string
+	Ranking      float32
+	Professional bool
+}
+
+func (p Player) Player() googs.Player {
+	return googs.Player{
+		ID:           p.ID,
+		Username:     p.Username,
+		Rank:         p.Ranking,
+		Professional: p.Professional,
+	}
+}
+
+// FindPlayer looks up a player by username or numeric player ID.
+func FindPlayer(c *googs.Client, nameOrID string) (*Player, error) {
+	nameOrID = strings.TrimSpace(nameOrID)
+	if id, err := strconv.ParseInt(nameOrID, 10, 64); err == nil {
+		res := Player{}
+		if err := get(c, fmt.Sprintf("/api/v1/players/%d", id), nil, &res); err != nil {
+			return nil, err
+		}
+		return &res, nil
+	}
+
+	params := url.Values{}
+	params.Set("username", nameOrID)
+	res := struct {
+		Results []Player
+	}{}
+	if err := get(c, "/api/v1/players/", params, &res); err != nil {
+		return nil, err
+	}
+	for _, p := range res.Results {
+		if strings.EqualFold(p.Username, nameOrID) {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("player %q not found", nameOrID)
+}
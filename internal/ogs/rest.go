@@ -0,0 +1,94 @@
+package ogs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ymattw/googs"
+)
+
+// All REST requests go through this, to the BaseURL of the client, see
+// NewClient().
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Send an authenticated GET request with optional query params.
+func get(c *googs.Client, uri string, params url.Values, ptr any) error {
+	if len(params) > 0 {
+		uri += "?" + params.Encode()
+	}
+	return send(c, "GET", uri, nil, ptr)
+}
+
+// Send an authenticated request with optional JSON body, decode the JSON
+// response into ptr unless it's nil.
+func send(c *googs.Client, method, uri string, body, ptr any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	url := c.BaseURL + uri
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	return do(req, ptr)
+}
+
+// Send an unauthenticated form, for OAuth token requests.
+func postForm(c *googs.Client, uri string, data url.Values, ptr any) error {
+	req, err := http.NewRequest("POST", c.BaseURL+uri, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(req, ptr)
+}
+
+func do(req *http.Request, ptr any) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s %s -> %w", req.Method, req.URL, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s -> %s %s", req.Method, req.URL, resp.Status, errorDetail(data))
+	}
+	if ptr == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, ptr)
+}
+
+// OGS reports errors as {"error": "..."} or {"detail": "..."}, mostly
+func errorDetail(data []byte) string {
+	res := struct {
+		Error  string
+		Detail string
+	}{}
+	if json.Unmarshal(data, &res) != nil {
+		return ""
+	}
+	if res.Error != "" {
+		return res.Error
+	}
+	return res.Detail
+}
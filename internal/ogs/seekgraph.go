@@ -0,0 +1,45 @@
+package ogs
+
+import (
+	"fmt"
+
+	"github.com/ymattw/googs"
+)
+
+// SeekEntry is an open challenge on the seek graph, or the removal of one
+// when Delete is set.
+type SeekEntry struct {
+	ChallengeID int64 `json:"challenge_id"`
+	GameID      int64 `json:"game_id"`
+	UserID      int64 `json:"user_id"`
+	Username    string
+	Ranking     float32
+	Name        string
+	Rules       string
+	Width       int
+	Height      int
+	Handicap    int // -1 for automatic
+	Komi        *float32
+	Ranked      bool
+	TimeControl string  `json:"time_control"`
+	TimePerMove float64 `json:"time_per_move"`
+	Delete      int
+}
+
+func (e *SeekEntry) Player() googs.Player {
+	return googs.Player{ID: e.UserID, Username: e.Username, Rank: e.Ranking}
+}
+
+// SeekGraphConnect subscribes to open challenges, fn is called with all
+// entries first, then with changes.
+func (s *Socket) SeekGraphConnect(fn func([]SeekEntry)) error {
+	callback := func(_ any, entries []SeekEntry) { fn(entries) }
+	if err := s.conn.On("seekgraph/global", callback); err != nil {
+		return fmt.Errorf("watch seek graph %w", err)
+	}
+	return s.conn.Emit("seek_graph/connect", map[string]any{"channel": "global"})
+}
+
+func (s *Socket) SeekGraphDisconnect() error {
+	return s.conn.Emit("seek_graph/disconnect", map[string]any{"channel": "global"})
+}
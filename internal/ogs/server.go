@@ -0,0 +1,22 @@
+package ogs
+
+import (
+	"fmt"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+)
+
+// NewClient creates a client not logged in yet, of an OGS compatible
+// deployment. All requests of the client go to the server.
+func NewClient(server config.Server, clientID, clientSecret string) *googs.Client {
+	c := googs.NewClient(clientID, clientSecret)
+	c.BaseURL, c.RealtimeURL = server.REST(), server.Realtime()
+	return c
+}
+
+// GameURL is the web page of the game on the server of the client.
+func GameURL(c *googs.Client, gameID int64) string {
+	return fmt.Sprintf("%s/game/%d", c.BaseURL, gameID)
+}
@@ -0,0 +1,88 @@
+package ogs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+)
+
+// Client of an httptest server with the handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) *googs.Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewClient(config.Server{
+		Host:        "127.0.0.1",
+		RESTURL:     srv.URL,
+		RealtimeURL: "ws" + strings.TrimPrefix(srv.URL, "http") + "/socket.io/",
+	}, "id", "secret")
+}
+
+func TestNewClient(t *testing.T) {
+	c := NewClient(config.Server{Host: "beta.online-go.com"}, "id", "secret")
+	if c.BaseURL != "https://beta.online-go.com" || c.RealtimeURL != "wss://beta.online-go.com/socket.io/?transport=websocket&EIO=3" {
+		t.Errorf("NewClient() endpoints %q, %q", c.BaseURL, c.RealtimeURL)
+	}
+	if got, want := GameURL(c, 1), "https://beta.online-go.com/game/1"; got != want {
+		t.Errorf("GameURL() = %q, want %q", got, want)
+	}
+}
+
+func TestServerREST(t *testing.T) {
+	var refreshed bool
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/token/":
+			if r.PostFormValue("grant_type") != "refresh_token" || r.PostFormValue("refresh_token") != "old" ||
+				r.PostFormValue("client_id") != "id" {
+				http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			refreshed = true
+			fmt.Fprint(w, `{"access_token": "new", "refresh_token": "next", "expires_in": 3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer new" {
+			http.Error(w, `{"detail": "bad token"}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/ui/config/":
+			fmt.Fprint(w, `{"user_jwt": "jwt"}`)
+		case "/api/v1/me":
+			fmt.Fprint(w, `{"id": 7, "username": "tenuki"}`)
+		case "/api/v1/games/1":
+			fmt.Fprint(w, `{"gamedata": {"width": 9, "height": 9}}`)
+		case "/api/v1/games/2":
+			fmt.Fprint(w, `{"gamedata": {"width": 9, "height": 13}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	c.AccessToken = "expired"
+	if err := identify(c); !IsAuthError(err) {
+		t.Errorf("identify() with expired token error %v, want auth error", err)
+	}
+	c.RefreshToken = "old"
+	if err := RefreshToken(c); err != nil {
+		t.Fatal(err)
+	}
+	if !refreshed || c.AccessToken != "new" || c.RefreshToken != "next" || c.UserJWT != "jwt" || c.ExpiresIn != 0 {
+		t.Errorf("RefreshToken() got %+v", c.Token)
+	}
+	if err := identify(c); err != nil || c.Username != "tenuki" || c.UserID != 7 {
+		t.Errorf("identify() = %v, user %q %d", err, c.Username, c.UserID)
+	}
+	if g, err := Game(c, 1); err != nil || g.Width != 9 {
+		t.Errorf("Game(1) = %v, %v", g, err)
+	}
+	if _, err := Game(c, 2); err == nil {
+		t.Error("Game(2) no error for non-square board")
+	}
+}
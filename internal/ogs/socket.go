@@ -0,0 +1,43 @@
+package ogs
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	socketio "github.com/graarh/golang-socketio"
+	"github.com/graarh/golang-socketio/transport"
+	"github.com/ymattw/googs"
+)
+
+// Socket is a realtime connection of our own, for messages googs does not
+// cover, as the googs.Client socket is unexported.
+type Socket struct {
+	conn *socketio.Client
+}
+
+// Connect establishes an authenticated realtime connection to the server of
+// the client.
+func Connect(c *googs.Client) (*Socket, error) {
+	conn, err := socketio.Dial(c.RealtimeURL, transport.GetDefaultWebsocketTransport())
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Emit("authenticate", map[string]any{"jwt": c.UserJWT}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Socket{conn: conn}, nil
+}
+
+func (s *Socket) Close() {
+	s.conn.Close()
+}
+
+// NewUUID returns a random version 4 UUID.
+func NewUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"errors"
+	"os"
+
+	"github.com/rivo/tview"
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+// Show the account picker instead of logging in, for multiple saved accounts.
+func (app *App) PickAccountOnStart() {
+	app.pickOnStart = true
+}
+
+const accountsPage = "accounts"
+
+// List saved accounts to switch to, or log in to another one
+func (app *App) showAccounts() {
+	returnPage, _ := app.root.GetFrontPage()
+	returnFocus := app.tui.GetFocus()
+	dismiss := func() {
+		app.root.RemovePage(accountsPage)
+		app.root.SwitchToPage(returnPage)
+		app.tui.SetFocus(returnFocus)
+	}
+
+	list := tview.NewList().ShowSecondaryText(false)
+	for _, name := range app.cfg.Server.SavedUsernames() {
+		name := name
+		current := app.client.LoggedIn() && name == app.client.Username
+		list.AddItem(name+util.Cond(current, " (current)", ""), "", 0, func() {
+			if current {
+				dismiss()
+				return
+			}
+			app.switchAccount(name, app.passphrase)
+		})
+	}
+	list.AddItem("Log in to another account", "", 0, func() {
+		app.root.RemovePage(accountsPage)
+		app.endSession()
+		app.client = ogs.NewClient(app.cfg.Server, app.client.ClientID, app.client.ClientSecret)
+		app.showLogin()
+	})
+	list.AddItem("Quit", "", 0, app.tui.Stop)
+	list.SetDoneFunc(func() {
+		if app.client.LoggedIn() {
+			dismiss()
+		}
+	})
+	list.SetBorder(true).
+		SetTitle(" Accounts (Esc to close) ")
+
+	// Center align the list in a 3x3 grid
+	grid := tview.NewGrid().
+		SetRows(-1, list.GetItemCount()+2, -1).
+		SetColumns(-1, 40, -1).
+		AddItem(list, 1, 1, 1, 1, 0, 0, true)
+	app.root.AddPage(accountsPage, grid, true, true)
+	app.tui.SetFocus(list)
+}
+
+// Load the saved account and make it current, the passphrase is prompted if
+// the secret file is encrypted with another one. The account picker stays
+// until switched, to fall back to on errors.
+func (app *App) switchAccount(username, passphrase string) {
+	if passphrase == "" {
+		passphrase = os.Getenv(config.PassphraseEnv)
+	}
+	var client *googs.Client
+	var wrongPassphrase bool
+
+	app.loading(
+		func() error {
+			c, p, err := ogs.LoadClient(app.cfg.Server, app.cfg.Server.SecretPath(username), func() (string, error) {
+				return passphrase, nil
+			})
+			if errors.Is(err, config.ErrWrongPassphrase) {
+				wrongPassphrase = true
+				return nil
+			}
+			client, passphrase = c, util.Cond(p != "", p, passphrase)
+			return err
+		},
+		func() {
+			if wrongPassphrase {
+				title := util.Cond(passphrase == "", "Passphrase of ", "Wrong passphrase, try again for ") + username
+				app.promptPassword(title, "Passphrase", func(p string) {
+					app.switchAccount(username, p)
+				})
+				return
+			}
+			app.root.RemovePage(accountsPage)
+			app.endSession()
+			app.client = client
+			app.passphrase = passphrase
+			app.info("Switched to account %s", username)
+			if client.LoggedIn() {
+				app.onLoggedIn()
+			} else {
+				app.showLogin()
+			}
+		})
+}
+
+// Tear down everything of the current account, game subscriptions and
+// connections included. The client is disconnected but kept for its fields.
+func (app *App) endSession() {
+	app.stopTV()
+	match := app.dropAutomatch()
+	for name, page := range app.pages {
+		if p, ok := page.(*gamePage); ok {
+			if app.root.HasPage(name) {
+				p.close(app)
+			}
+			continue
+		}
+		if p, ok := page.(interface{ stop(*App) }); ok {
+			p.stop(app)
+		}
+		app.root.RemovePage(name)
+	}
+	app.pages = make(map[string]Page)
+
+	app.socketLock.Lock()
+	if s := app.socket; s != nil {
+		app.socket = nil
+		// Cancel the search before closing, both in background
+		go func() {
+			if match != nil {
+				if err := s.CancelMatch(match.uuid); err != nil {
+					app.error("Cancel automatch %v", err)
+				}
+			}
+			s.Close()
+		}()
+	}
+	app.socketLock.Unlock()
+
+	app.stopControl()
+	if app.session != nil {
+		close(app.session)
+		app.session = nil
+	}
+	app.client.Disconnect()
+	app.nextBoard = make(map[int64]*googs.GameListEntry)
+	app.currentGameID = 0
+	app.prefsLock.Lock()
+	app.prefs = &config.Preferences{}
+	app.prefsLock.Unlock()
+}
+
+// Show the login page, recreated to pick up client fields
+func (app *App) showLogin() {
+	app.root.RemovePage("login")
+	app.addLoginPage()
+	app.root.SwitchToPage("login")
+}
+
+var errLoginNeeded = errors.New("login expired, please log in again")
+
+// Exchange the refresh token after the access token was rejected, and save
+// the new tokens. errLoginNeeded is returned if the refresh token is rejected
+// too. Must NOT be called from the UI goroutine.
+func (app *App) reauthenticate(rejectedToken string) error {
+	app.authLock.Lock()
+	defer app.authLock.Unlock()
+	if app.client.AccessToken != rejectedToken {
+		return nil // Refreshed by a concurrent caller
+	}
+	if err := ogs.RefreshToken(app.client); err != nil {
+		app.warn("Refresh token %v", err)
+		return errLoginNeeded
+	}
+	app.info("Access token of %s refreshed", app.client.Username)
+	if err := app.saveSecret(); err != nil {
+		app.error("Save secret %v", err)
+	}
+	return nil
+}
+
+// Route to the login page with the username prefilled after the saved login
+// stopped working, the pending page is opened once logged in.
+func (app *App) relogin(pending string) {
+	if front, _ := app.root.GetFrontPage(); front == "login" {
+		return // Already there from another failed request
+	}
+	app.notify("%v", errLoginNeeded)
+	username := app.client.Username
+	app.endSession()
+	app.client = ogs.NewClient(app.cfg.Server, app.client.ClientID, app.client.ClientSecret)
+	app.client.Username = username
+	app.resumePage = pending
+	app.showLogin()
+}
@@ -2,10 +2,12 @@ package tui
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"net"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -13,23 +15,58 @@ import (
 	"github.com/ymattw/googs"
 
 	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
 )
 
 type App struct {
+	// Connection measurement (milliseconds), updated from the socket and
+	// accessed atomically. First in the struct for 64-bit alignment on
+	// 32-bit platforms.
+	drift   int64
+	latency int64
+
 	client *googs.Client
 	tui    *tview.Application
 	root   *tview.Pages
 	logger *tview.TextView
 	pages  map[string]Page
+	prefs  *config.Preferences
+	cfg    *config.Config
+	keys   *keymap
+
+	passphrase string // Of the secret file, empty to save it unencrypted
+
+	// Guards replacing prefs and its follow list, which are also accessed
+	// from background polling. Other fields are for the UI goroutine only.
+	prefsLock sync.Mutex
 
-	// Connection measurement (milliseconds)
-	drift      int64
-	latency    int64
-	pingTicker *time.Ticker
+	// Closed when the logged in account is switched, to stop its goroutines
+	session      chan struct{}
+	sessionStart time.Time
+	pickOnStart  bool // Show the account picker instead of logging in
+
+	authLock   sync.Mutex // Serializes token refreshes
+	resumePage string     // Page to go to after logging in again
 
 	// Next actionable board to move on, key is gameID
 	nextBoard     map[int64]*googs.GameListEntry
 	currentGameID int64
+
+	notifications notifications
+	navStatus     *tview.TextView // Shown on the left of navbars
+
+	// Our own realtime connection, see realtime()
+	socket     *ogs.Socket
+	socketLock sync.Mutex
+	match      *automatch // Nil when not searching
+
+	control net.Listener // Control socket, nil if not listening
+
+	// TV mode, nil when off
+	tv         *tvMode
+	tvInterval time.Duration
+	tvOnStart  bool
 }
 
 type Page interface {
@@ -40,14 +77,41 @@ type Page interface {
 	Leave(*App)                    // Clean up and switch page (when Esc pressed)
 }
 
-func NewApp(client *googs.Client) *App {
+// Optional for pages holding subscriptions only needed while shown
+type hidingPage interface {
+	Hide(*App) // Called on the UI goroutine when switched away from
+}
+
+// NewApp creates the app, an error is returned if the config is invalid.
+func NewApp(client *googs.Client, cfg *config.Config) (*App, error) {
+	if err := addBoardThemes(cfg.UI.BoardThemes); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ConfigPath(), err)
+	}
+	if _, ok := boardThemes[cfg.UI.BoardTheme]; !ok {
+		return nil, fmt.Errorf("%s: ui.board_theme %q is not one of %s",
+			config.ConfigPath(), cfg.UI.BoardTheme, strings.Join(boardThemeNames(), ", "))
+	}
+	if _, ok := uiSchemes[cfg.UI.Scheme]; !ok {
+		return nil, fmt.Errorf("%s: ui.scheme %q is not one of %s",
+			config.ConfigPath(), cfg.UI.Scheme, strings.Join(uiSchemeNames(), ", "))
+	}
+	applyUIScheme(cfg.UI.Scheme)
+	keys, err := newKeymap(cfg.UI.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: ui.keys: %w", config.ConfigPath(), err)
+	}
+	glyphs = glyphSets[cfg.UI.Glyphs]
+
 	app := &App{
-		client:     client,
-		tui:        tview.NewApplication(),
-		root:       tview.NewPages(),
-		pages:      make(map[string]Page),
-		pingTicker: time.NewTicker(10 * time.Second),
-		nextBoard:  make(map[int64]*googs.GameListEntry),
+		client:    client,
+		cfg:       cfg,
+		keys:      keys,
+		tui:       tview.NewApplication(),
+		root:      tview.NewPages(),
+		pages:     make(map[string]Page),
+		prefs:     &config.Preferences{},
+		navStatus: tview.NewTextView(),
+		nextBoard: make(map[int64]*googs.GameListEntry),
 	}
 
 	// Too small screen leads to tab switching focus to invisble
@@ -56,29 +120,51 @@ func NewApp(client *googs.Client) *App {
 		w, h := screen.Size()
 		if w < 70 || h < 30 {
 			msg := "Screen too small, make it at least 70x30."
-			tview.Print(screen, msg, 0, 0, len(msg), tview.AlignLeft, solarizedRed)
+			tview.Print(screen, msg, 0, 0, len(msg), tview.AlignLeft, Colors.NotificationColor)
 			return true
 		}
 		return false
 	})
+	app.tui.SetAfterDrawFunc(app.drawNotifications)
+	app.navStatus.SetDynamicColors(true).
+		SetTextColor(Styles.TertiaryTextColor)
 
 	app.initLogger()
 	app.info("App initialized")
 
-	// Precreated pages which are not required to follow Page interface
+	app.addLoginPage()
+	return app, nil
+}
+
+// Precreated pages which are not required to follow Page interface
+func (app *App) addLoginPage() {
 	app.root.AddPage("login", newLoginPage(app, func() {
-		path := config.SecretPath(app.client.Username)
-		// Create directories to path or the initial save will fail on linux
-		if err := os.MkdirAll(filepath.Dir(path), 0770); err != nil {
-			panic(err)
-		}
-		if err := app.client.Save(path); err != nil {
+		if err := app.saveSecret(); err != nil {
 			panic(err)
 		}
 		app.onLoggedIn()
 	}), true, false)
+}
 
-	return app
+// Save the client to the secret file, encrypted if a passphrase is set
+func (app *App) saveSecret() error {
+	return ogs.SaveClient(app.cfg.Server.SecretPath(app.client.Username), app.client, app.passphrase)
+}
+
+// Set the passphrase to encrypt the secret file with when saved.
+func (app *App) SetPassphrase(passphrase string) {
+	app.passphrase = passphrase
+}
+
+// Pages created upon logged in, game pages are created on demand
+var staticPages = []string{"home", "watch", "history", "challenge", "seek"}
+
+func (app *App) addStaticPages() {
+	app.addPage("home", newHomePage(app))
+	app.addPage("watch", newWatchPage(app))
+	app.addPage("history", newHistoryPage(app))
+	app.addPage("challenge", newChallengePage(app))
+	app.addPage("seek", newSeekPage(app))
 }
 
 func (app *App) addPage(name string, page Page) {
@@ -87,12 +173,24 @@ func (app *App) addPage(name string, page Page) {
 	app.setupCommonKeys(page)
 }
 
-// This is expected to be called only once upon logged in
+// This is expected to be called once upon logged in, and again after
+// endSession() when switching accounts
 func (app *App) onLoggedIn() {
+	app.session = make(chan struct{})
+	app.sessionStart = time.Now()
+	if prefs, err := config.LoadPrefs(app.cfg.Server, app.client.Username); err != nil {
+		app.warn("Load preferences %v", err)
+	} else {
+		app.prefsLock.Lock()
+		app.prefs = prefs
+		app.prefsLock.Unlock()
+	}
+
 	app.client.NetPing(0, 0) // Initial ping
 	app.client.OnNetPong(func(drift, latency int64) {
 		// app.debug("Server pong drift=%d latency=%d", drift, latency)
-		app.drift, app.latency = drift, latency
+		atomic.StoreInt64(&app.drift, drift)
+		atomic.StoreInt64(&app.latency, latency)
 	})
 	app.client.OnActiveGame(func(g *googs.GameListEntry) {
 		app.info("Active game update of #%d", g.ID)
@@ -111,37 +209,76 @@ func (app *App) onLoggedIn() {
 		}
 	})
 
-	go func() {
-		for range app.pingTicker.C {
-			app.client.NetPing(app.drift, app.latency)
+	go func(done <-chan struct{}) {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				app.client.NetPing(atomic.LoadInt64(&app.drift), atomic.LoadInt64(&app.latency))
+			}
 		}
-	}()
+	}(app.session)
+	go app.pollFollowed(app.session)
+	app.startControl()
 
-	app.addPage("home", newHomePage(app))
-	app.addPage("watch", newWatchPage(app))
-	app.switchToPage("home")
+	if name := app.uiScheme(); name != appliedUIScheme {
+		applyUIScheme(name)
+		app.restyleShared()
+	}
+	app.addStaticPages()
+	resume := app.resumePage
+	app.resumePage = ""
+	if app.tvOnStart {
+		app.tvOnStart = false
+		app.startTV()
+	} else if id, err := strconv.ParseInt(resume, 10, 64); err == nil {
+		app.switchToNewGamePage(id, "home")
+	} else if app.pages[resume] != nil {
+		app.switchToPage(resume)
+	} else {
+		app.switchToPage(app.cfg.UI.StartPage)
+	}
 }
 
 func (app *App) Run() error {
-	if app.client.LoggedIn() {
+	if app.pickOnStart {
+		app.showAccounts()
+	} else if app.client.LoggedIn() {
 		app.onLoggedIn()
 	} else {
 		app.root.SwitchToPage("login")
 	}
 	app.tui.SetRoot(app.root, true)
 	app.info("App started running")
+	defer app.stopControl()
 	return app.tui.Run()
 }
 
+func (app *App) savePrefs() {
+	app.prefsLock.Lock()
+	defer app.prefsLock.Unlock()
+	if err := app.prefs.Save(app.cfg.Server, app.client.Username); err != nil {
+		app.error("Save preferences %v", err)
+	}
+}
+
 // Always safe to call from no matter where
 func (app *App) redraw(fn func()) {
-	fn = cond(fn != nil, fn, func() {})
+	fn = util.Cond(fn != nil, fn, func() {})
 	go func() {
 		app.tui.QueueUpdateDraw(fn)
 	}()
 }
 
 func (app *App) switchToPage(name string) {
+	if front, _ := app.root.GetFrontPage(); front != name {
+		if p, ok := app.pages[front].(hidingPage); ok {
+			p.Hide(app)
+		}
+	}
 	app.loading(
 		func() error {
 			// Show target page now, instead of the last visible
@@ -197,7 +334,7 @@ func (app *App) nextGameEntry() *googs.GameListEntry {
 	return app.nextBoard[gameIDs[0]]
 }
 
-var commonKeyDescriptions = []string{"Home", "Next", "Watch", "quit"}
+var commonHints = []string{"home", "next", "watch", "scheme", "accounts", "quit"}
 
 // Set up common shortcuts. Page Root() must be a Grid.
 func (app *App) setupCommonKeys(p Page) {
@@ -234,14 +371,14 @@ func (app *App) setupCommonKeys(p Page) {
 			return event
 		}
 
-		switch event.Rune() {
-		case 'D':
+		switch app.keys.action(commonScope, event) {
+		case "logs":
 			app.showLogger()
 			return nil
-		case 'H':
+		case "home":
 			app.switchToPage("home")
 			return nil
-		case 'N':
+		case "next":
 			if g := app.nextGameEntry(); g != nil {
 				curPage, _ := app.root.GetFrontPage()
 				if _, err := strconv.ParseInt(curPage, 10, 64); err == nil {
@@ -253,10 +390,16 @@ func (app *App) setupCommonKeys(p Page) {
 				app.switchToPage("home")
 			}
 			return nil
-		case 'W':
+		case "watch":
 			app.switchToPage("watch")
 			return nil
-		case 'q':
+		case "accounts":
+			app.showAccounts()
+			return nil
+		case "scheme":
+			app.notify("Color scheme %s", app.cycleUIScheme())
+			return nil
+		case "quit":
 			p.Leave(app)
 			return nil
 		}
@@ -0,0 +1,245 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/ogs"
+)
+
+var (
+	automatchSizes  = []string{"9x9", "13x13", "19x19"}
+	automatchSpeeds = []string{"blitz", "rapid", "live", "correspondence"}
+	// Empty for any
+	automatchRules        = []string{"", "japanese", "chinese", "aga", "korean", "nz", "ing"}
+	automatchTimeControls = []string{"", "byoyomi", "fischer", "canadian", "simple"}
+)
+
+// An automatch search in progress
+type automatch struct {
+	uuid    string
+	desc    string
+	started time.Time
+	popup   string // Page name of the waiting modal
+	done    chan struct{}
+}
+
+// Connect our own realtime socket on first use
+func (app *App) realtime() (*ogs.Socket, error) {
+	app.socketLock.Lock()
+	defer app.socketLock.Unlock()
+	if app.socket != nil {
+		return app.socket, nil
+	}
+	s, err := ogs.Connect(app.client)
+	if err != nil {
+		return nil, err
+	}
+	app.socket = s
+	return s, nil
+}
+
+// Show the automatch form, or the waiting modal if searching already
+func (app *App) startAutomatch() {
+	if app.match != nil {
+		app.automatchWaiting()
+		return
+	}
+
+	prefs := app.prefs.Automatch
+	if prefs.Size == "" {
+		prefs = config.Automatch{Size: "19x19", Speed: "live", LowerRankDiff: 3, UpperRankDiff: 3}
+	}
+
+	returnPage, _ := app.root.GetFrontPage()
+	returnFocus := app.tui.GetFocus()
+	pageName := returnPage + "-automatch"
+	dismiss := func() {
+		app.root.RemovePage(pageName)
+		app.root.SwitchToPage(returnPage)
+		app.tui.SetFocus(returnFocus)
+	}
+
+	lower := strconv.Itoa(prefs.LowerRankDiff)
+	upper := strconv.Itoa(prefs.UpperRankDiff)
+	status := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	form := tview.NewForm().
+		AddDropDown("Size", automatchSizes, indexOf(automatchSizes, prefs.Size), func(option string, _ int) {
+			prefs.Size = option
+		}).
+		AddDropDown("Speed", automatchSpeeds, indexOf(automatchSpeeds, prefs.Speed), func(option string, _ int) {
+			prefs.Speed = option
+		}).
+		AddInputField("Ranks below", lower, 4, nil, func(text string) { lower = text }).
+		AddInputField("Ranks above", upper, 4, nil, func(text string) { upper = text }).
+		AddDropDown("Rules", anyOf(automatchRules), indexOf(automatchRules, prefs.Rules), func(_ string, i int) {
+			prefs.Rules = automatchRules[i]
+		}).
+		AddDropDown("Time control", anyOf(automatchTimeControls), indexOf(automatchTimeControls, prefs.TimeControl), func(_ string, i int) {
+			prefs.TimeControl = automatchTimeControls[i]
+		})
+	form.SetButtonsAlign(tview.AlignCenter).
+		AddButton("Search", func() {
+			var err error
+			if prefs.LowerRankDiff, err = strconv.Atoi(strings.TrimSpace(lower)); err != nil || prefs.LowerRankDiff < 0 {
+				status.SetText("[red]Invalid ranks below[-]")
+				return
+			}
+			if prefs.UpperRankDiff, err = strconv.Atoi(strings.TrimSpace(upper)); err != nil || prefs.UpperRankDiff < 0 {
+				status.SetText("[red]Invalid ranks above[-]")
+				return
+			}
+			app.prefs.Automatch = prefs
+			app.savePrefs()
+			dismiss()
+			app.findMatch(prefs)
+		}).
+		AddButton("Cancel", dismiss).
+		SetCancelFunc(dismiss).
+		SetTitle(" Automatch ").
+		SetBorder(true)
+
+	// Center align the form and bottom status in a 4x3 grid
+	grid := tview.NewGrid().
+		SetRows(-1, form.GetFormItemCount()*2+5, 1, -1).
+		SetColumns(-1, 40, -1).
+		AddItem(form, 1, 1, 1, 1, 0, 0, true).
+		AddItem(status, 2, 1, 1, 1, 0, 0, false)
+	app.root.AddPage(pageName, grid, true, true)
+	app.tui.SetFocus(form)
+}
+
+func (app *App) findMatch(prefs config.Automatch) {
+	desc := []string{prefs.Size, prefs.Speed}
+	for _, s := range []string{prefs.Rules, prefs.TimeControl} {
+		if s != "" {
+			desc = append(desc, s)
+		}
+	}
+	m := &automatch{
+		uuid:    ogs.NewUUID(),
+		desc:    strings.Join(desc, " "),
+		started: time.Now(),
+		done:    make(chan struct{}),
+	}
+	req := &ogs.AutomatchRequest{
+		UUID:          m.uuid,
+		Size:          prefs.Size,
+		Speed:         prefs.Speed,
+		LowerRankDiff: prefs.LowerRankDiff,
+		UpperRankDiff: prefs.UpperRankDiff,
+		Rules:         prefs.Rules,
+		TimeControl:   prefs.TimeControl,
+	}
+
+	app.loading(
+		func() error {
+			s, err := app.realtime()
+			if err != nil {
+				app.error("Connect realtime %v", err)
+				return err
+			}
+			if err := s.OnMatchStart(app.onMatchStart); err != nil {
+				return err
+			}
+			if err := s.FindMatch(req); err != nil {
+				app.error("Find match %v", err)
+				return err
+			}
+			app.info("Automatch %s started", m.uuid)
+			return nil
+		},
+		func() {
+			app.match = m
+			go app.automatchTicker(m)
+			app.automatchWaiting()
+		},
+	)
+}
+
+// Keep the navbar status updated until the search is done
+func (app *App) automatchTicker(m *automatch) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			app.redraw(func() { app.navStatus.SetText("") })
+			return
+		case <-ticker.C:
+			elapsed := time.Since(m.started).Truncate(time.Second)
+			app.redraw(func() {
+				app.navStatus.SetText(fmt.Sprintf("🔍 Automatch %s %s", m.desc, elapsed))
+			})
+		}
+	}
+}
+
+func (app *App) automatchWaiting() {
+	m := app.match
+	message := fmt.Sprintf("Searching for a %s match ...\n\nHide to keep browsing meanwhile.", m.desc)
+	m.popup = app.popUp(message, []string{"Hide", "Cancel"}, map[string]func(){
+		"Cancel": app.cancelAutomatch,
+	})
+}
+
+// Forget the pending search, nil if none
+func (app *App) dropAutomatch() *automatch {
+	m := app.match
+	if m == nil {
+		return nil
+	}
+	app.match = nil
+	close(m.done)
+	return m
+}
+
+func (app *App) cancelAutomatch() {
+	m := app.dropAutomatch()
+	if m == nil {
+		return
+	}
+	app.loading(
+		func() error {
+			s, err := app.realtime()
+			if err != nil {
+				app.error("Connect realtime %v", err)
+				return err
+			}
+			if err := s.CancelMatch(m.uuid); err != nil {
+				app.error("Cancel automatch %v", err)
+				return err
+			}
+			app.info("Automatch %s canceled", m.uuid)
+			return nil
+		},
+		func() {},
+	)
+}
+
+// Called from the socket when a match is found
+func (app *App) onMatchStart(uuid string, gameID int64) {
+	app.redraw(func() {
+		m := app.match
+		if m == nil || m.uuid != uuid {
+			return
+		}
+		app.match = nil
+		close(m.done)
+		app.info("Automatch %s found game %d", uuid, gameID)
+		app.notify("Match found, game %d", gameID)
+
+		// Dismiss the waiting modal if still shown
+		if app.root.HasPage(m.popup) {
+			app.root.RemovePage(m.popup)
+		}
+		app.switchToNewGamePage(gameID, "home")
+	})
+}
@@ -2,9 +2,12 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/util"
 )
 
 const (
@@ -17,6 +20,34 @@ const (
 	DeadWhiteStone = '◽'
 )
 
+// Glyphs to draw a board with. Each point takes 2 columns, Fill is drawn
+// in the second column unless the glyphs are full-width.
+type Glyphs struct {
+	Grid, Hoshi          rune
+	Black, White         rune
+	DeadBlack, DeadWhite rune
+	Fill                 rune // 0 for full-width glyphs
+	ColumnA              rune // Label of the first column
+}
+
+var glyphSets = map[string]Glyphs{
+	"fullwidth": {
+		Grid: GridChar, Hoshi: HoshiChar,
+		Black: BlackStone, White: WhiteStone,
+		DeadBlack: DeadBlackStone, DeadWhite: DeadWhiteStone,
+		ColumnA: 'Ａ', // Full-width Latin capital A
+	},
+	"ascii": {
+		Grid: '.', Hoshi: '+',
+		Black: 'X', White: 'O',
+		DeadBlack: 'x', DeadWhite: 'o',
+		Fill: ' ', ColumnA: 'A',
+	},
+}
+
+// Glyphs in use, set from config
+var glyphs = glyphSets["fullwidth"]
+
 var (
 	hoshiPoints = map[int][]googs.OriginCoordinate{
 		9: {
@@ -50,9 +81,10 @@ type Cell struct {
 	isLastMove bool
 	isHoshi    bool
 	isRemoval  bool
+	territory  Stone // Owner of the empty (or dead stone) point when scoring
 }
 
-func newCell(g *googs.GameState, row, col int) Cell {
+func newCell(g *googs.GameState, territory [][]Stone, row, col int) Cell {
 	isHoshi := false
 	hPoints := hoshiPoints[g.BoardSize()]
 	for _, h := range hPoints {
@@ -65,43 +97,112 @@ func newCell(g *googs.GameState, row, col int) Cell {
 		isLastMove: g.LastMove.X == col && g.LastMove.Y == row,
 		isHoshi:    isHoshi,
 		isRemoval:  g.Removal[row][col] == 1,
+		territory:  territory[row][col],
 	}
 }
 
+// Estimate territory when scoring: empty regions (dead stones taken as empty)
+// bordered by stones of one color only. All empty when not scoring.
+func estimateTerritory(g *googs.GameState) [][]Stone {
+	size := g.BoardSize()
+	territory := make([][]Stone, size)
+	for row := range territory {
+		territory[row] = make([]Stone, size)
+	}
+	scoring := g.Phase == googs.StoneRemovalPhase ||
+		(g.Phase == googs.FinishedPhase && strings.Contains(g.Outcome, "point"))
+	if !scoring {
+		return territory
+	}
+
+	stoneAt := func(row, col int) Stone {
+		if g.Removal[row][col] == 1 {
+			return Empty
+		}
+		return Stone(g.Board[row][col])
+	}
+	visited := make([][]bool, size)
+	for row := range visited {
+		visited[row] = make([]bool, size)
+	}
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			if visited[row][col] || stoneAt(row, col) != Empty {
+				continue
+			}
+			// Flood fill the region, noting bordering colors
+			region := []googs.OriginCoordinate{}
+			borders := map[Stone]bool{}
+			queue := []googs.OriginCoordinate{{X: col, Y: row}}
+			visited[row][col] = true
+			for len(queue) > 0 {
+				p := queue[0]
+				queue = queue[1:]
+				region = append(region, p)
+				for _, d := range []googs.OriginCoordinate{{X: -1}, {X: 1}, {Y: -1}, {Y: 1}} {
+					x, y := p.X+d.X, p.Y+d.Y
+					if x < 0 || y < 0 || x >= size || y >= size {
+						continue
+					}
+					if s := stoneAt(y, x); s != Empty {
+						borders[s] = true
+					} else if !visited[y][x] {
+						visited[y][x] = true
+						queue = append(queue, googs.OriginCoordinate{X: x, Y: y})
+					}
+				}
+			}
+			if len(borders) != 1 {
+				continue // Neutral, or an empty board
+			}
+			owner := util.Cond(borders[Black], Black, White)
+			for _, p := range region {
+				territory[p.Y][p.X] = owner
+			}
+		}
+	}
+	return territory
+}
+
 func (c Cell) content() rune {
 	if c.stone == Empty && c.isHoshi {
-		return HoshiChar
+		return glyphs.Hoshi
 	}
 	if c.stone == Black && c.isRemoval {
-		return DeadBlackStone
+		return glyphs.DeadBlack
 	}
 	if c.stone == White && c.isRemoval {
-		return DeadWhiteStone
+		return glyphs.DeadWhite
 	}
 	return map[Stone]rune{
-		Empty: GridChar,
-		Black: BlackStone,
-		White: WhiteStone,
+		Empty: glyphs.Grid,
+		Black: glyphs.Black,
+		White: glyphs.White,
 	}[c.stone]
 }
 
-func (c Cell) foreground(theme string) tcell.Color {
-	return boardThemes[theme].GridFG
+func (c Cell) foreground(theme *BoardTheme) tcell.Color {
+	return theme.GridFG
 }
 
-func (c Cell) background(theme string) tcell.Color {
-	bg := boardThemes[theme].BoardBG
-
-	if c.isLastMove && c.stone == Black && !c.isRemoval {
-		bg = boardThemes[theme].LastBlackBG
-	} else if c.isLastMove && c.stone == White && !c.isRemoval {
-		bg = boardThemes[theme].LastWhiteBG
+func (c Cell) background(theme *BoardTheme) tcell.Color {
+	switch {
+	case c.isRemoval && c.stone != Empty:
+		return theme.DeadBG
+	case c.isLastMove && c.stone == Black:
+		return theme.LastBlackBG
+	case c.isLastMove && c.stone == White:
+		return theme.LastWhiteBG
+	case c.territory == Black:
+		return theme.TerritoryBlackBG
+	case c.territory == White:
+		return theme.TerritoryWhiteBG
 	}
-	return tcell.NewHexColor(int32(bg))
+	return theme.BoardBG
 }
 
 func colLabel(col int) rune {
-	letter := 'Ａ' + rune(col) // Full-width Latin capital A
+	letter := glyphs.ColumnA + rune(col)
 	if col >= 8 {
 		letter += 1
 	}
@@ -122,13 +223,29 @@ func colLabel(col int) rune {
 //	1 〸〸〸〸〸〸〸〸〸 1
 //	  ＡＢＣＤＥＦＧＨＪ
 func (p *gamePage) drawBoard(screen tcell.Screen, x, y int) (int, int, int, int) {
-	size := p.gameState.BoardSize()
 	whoseTurn := p.game.WhoseTurn(p.gameState)
+	return drawBoard(screen, x, y, p.gameState, p.boardTheme, p.cursor, whoseTurn)
+}
+
+// Draw the board of given game state at (x, y), cursor is hidden when its
+// coordinate is out of the board.
+func drawBoard(screen tcell.Screen, x, y int, state *googs.GameState, themeName string, cursor *googs.OriginCoordinate, whoseTurn googs.PlayerColor) (int, int, int, int) {
+	size := state.BoardSize()
+	theme, ok := boardThemes[themeName]
+	if !ok {
+		theme = boardThemes["night"]
+	}
+	// Highlight with attributes as well on low color terminals, or when a
+	// color is not distinguishable from the board
+	colors := screen.Colors()
+	theme = theme.fit(colors)
+	lowColor := colors < 256
+	territory := estimateTerritory(state)
 
 	// Top coordinate labels (A, B, C, ... skipping I)
 	for c := 0; c < size; c++ {
 		// NOTE: 3-char offset for row numbers on the left, label runes
-		// are Full-width.
+		// are Full-width unless glyphs are ascii.
 		screen.SetContent(x+3+c*2, y, colLabel(c), nil, StyleDefault)
 	}
 
@@ -140,17 +257,27 @@ func (p *gamePage) drawBoard(screen tcell.Screen, x, y int) (int, int, int, int)
 		}
 
 		for col := 0; col < size; col++ {
-			cell := newCell(p.gameState, row, col)
+			cell := newCell(state, territory, row, col)
 			style := StyleDefault.
-				Foreground(cell.foreground(p.boardTheme)).
-				Background(cell.background(p.boardTheme))
+				Foreground(cell.foreground(&theme)).
+				Background(cell.background(&theme))
+			if cell.isLastMove && cell.stone != Empty && !cell.isRemoval &&
+				(lowColor || cell.background(&theme) == theme.BoardBG) {
+				style = style.Bold(true).Underline(true)
+			}
 			// Cursor use current shape in cell with reversed fg
-			if col == p.cursor.X && row == p.cursor.Y {
-				color := cond(whoseTurn == googs.PlayerBlack, tcell.ColorBlack, tcell.ColorWhite)
+			if col == cursor.X && row == cursor.Y {
+				color := util.Cond(whoseTurn == googs.PlayerBlack, theme.CursorBlack, theme.CursorWhite)
 				style = style.Background(color)
+				if lowColor || color == theme.BoardBG {
+					style = style.Reverse(true).Blink(true)
+				}
 			}
-			// NOTE: cell runes are Full-width.
+			// NOTE: cell runes are Full-width unless glyphs are ascii.
 			screen.SetContent(x+3+col*2, y+1+row, cell.content(), nil, style)
+			if glyphs.Fill != 0 {
+				screen.SetContent(x+4+col*2, y+1+row, glyphs.Fill, nil, style)
+			}
 		}
 
 		// A space and right side coordinate label (19, 18, .., 1)
@@ -169,3 +296,123 @@ func (p *gamePage) drawBoard(screen tcell.Screen, x, y int) (int, int, int, int)
 	screen.Show()
 	return x, y, size*2 + 6, size + 2
 }
+
+// Replay moves of a game to build a board snapshot locally, which saves
+// a GameState() API call when only a glance of the board is needed.
+func replayGameState(g *googs.Game) *googs.GameState {
+	size := g.BoardSize()
+	state := &googs.GameState{
+		Phase:        g.Phase,
+		MoveNumber:   len(g.Moves),
+		LastMove:     googs.OriginCoordinate{X: -1, Y: -1},
+		PlayerToMove: g.Clock.CurrentPlayerID,
+		Board:        make([][]int, size),
+		Removal:      make([][]int, size),
+	}
+	for row := 0; row < size; row++ {
+		state.Board[row] = make([]int, size)
+		state.Removal[row] = make([]int, size)
+	}
+
+	// Fixed handicap stones are the initial state instead of moves, and white
+	// moves first then
+	if g.Handicap > 1 && g.InitialPlayer == "white" {
+		for _, c := range handicapPoints(size, g.Handicap) {
+			state.Board[c.Y][c.X] = int(Black)
+		}
+	}
+
+	// Black places free handicap stones in a row, then players alternate
+	freeHandicap := g.Handicap > 1 && g.InitialPlayer != "white"
+	color := util.Cond(g.InitialPlayer == "white", White, Black)
+	for i, m := range g.Moves {
+		stone := color
+		if freeHandicap && i < g.Handicap {
+			stone, color = Black, White // White moves after the placement
+		} else {
+			color = util.Cond(color == Black, White, Black)
+		}
+
+		state.LastMove = m.OriginCoordinate
+		if m.IsPass() || m.X >= size || m.Y >= size {
+			continue
+		}
+		state.Board[m.Y][m.X] = int(stone)
+
+		for _, n := range neighbors(m.OriginCoordinate, size) {
+			if state.Board[n.Y][n.X] == int(stone) || state.Board[n.Y][n.X] == int(Empty) {
+				continue
+			}
+			if group, liberties := groupOf(state.Board, n); liberties == 0 {
+				for _, s := range group {
+					state.Board[s.Y][s.X] = int(Empty)
+				}
+			}
+		}
+		// Self capture, only possible when allowed by rules
+		if group, liberties := groupOf(state.Board, m.OriginCoordinate); liberties == 0 {
+			for _, s := range group {
+				state.Board[s.Y][s.X] = int(Empty)
+			}
+		}
+	}
+	return state
+}
+
+// Star points for fixed handicap stones in the traditional order: upper right,
+// lower left, lower right, upper left, then center and sides. Nil if the board
+// is too small.
+func handicapPoints(size, handicap int) []googs.OriginCoordinate {
+	if size < 7 || handicap < 2 || handicap > 9 {
+		return nil
+	}
+	lo := util.Cond(size >= 13, 3, 2)
+	hi, mid := size-1-lo, size/2
+	point := func(x, y int) googs.OriginCoordinate { return googs.OriginCoordinate{X: x, Y: y} }
+
+	points := []googs.OriginCoordinate{point(hi, lo), point(lo, hi), point(hi, hi), point(lo, lo)}
+	if handicap <= 4 {
+		return points[:handicap]
+	}
+	if handicap >= 6 {
+		points = append(points, point(lo, mid), point(hi, mid))
+	}
+	if handicap >= 8 {
+		points = append(points, point(mid, lo), point(mid, hi))
+	}
+	if handicap%2 == 1 {
+		points = append(points, point(mid, mid))
+	}
+	return points
+}
+
+func neighbors(c googs.OriginCoordinate, size int) []googs.OriginCoordinate {
+	var res []googs.OriginCoordinate
+	for _, d := range []googs.OriginCoordinate{{X: -1}, {X: 1}, {Y: -1}, {Y: 1}} {
+		x, y := c.X+d.X, c.Y+d.Y
+		if x >= 0 && x < size && y >= 0 && y < size {
+			res = append(res, googs.OriginCoordinate{X: x, Y: y})
+		}
+	}
+	return res
+}
+
+// Return stones of the group at given coordinate and count of its liberties
+func groupOf(board [][]int, c googs.OriginCoordinate) ([]googs.OriginCoordinate, int) {
+	stone := board[c.Y][c.X]
+	visited := map[googs.OriginCoordinate]bool{c: true}
+	liberties := make(map[googs.OriginCoordinate]bool)
+	group := []googs.OriginCoordinate{c}
+	for i := 0; i < len(group); i++ {
+		for _, n := range neighbors(group[i], len(board)) {
+			switch {
+			case board[n.Y][n.X] == int(Empty):
+				liberties[n] = true
+			case board[n.Y][n.X] == stone && !visited[n]:
+				visited[n] = true
+				group = append(group, n)
+			}
+		}
+	}
+	return group, len(liberties)
+}
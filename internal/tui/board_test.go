@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ymattw/googs"
+)
+
+// Parse rows of "." (empty), "X" (black) and "O" (white) into a board
+func parseBoard(rows ...string) [][]int {
+	board := make([][]int, len(rows))
+	for y, row := range rows {
+		board[y] = make([]int, len(row))
+		for x, r := range row {
+			switch r {
+			case 'X':
+				board[y][x] = int(Black)
+			case 'O':
+				board[y][x] = int(White)
+			}
+		}
+	}
+	return board
+}
+
+func formatBoard(board [][]int) string {
+	var sb strings.Builder
+	for _, row := range board {
+		for _, v := range row {
+			sb.WriteByte(".XO"[v])
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func moves(coords ...[2]int) []googs.Move {
+	var res []googs.Move
+	for _, c := range coords {
+		res = append(res, googs.Move{OriginCoordinate: googs.OriginCoordinate{X: c[0], Y: c[1]}})
+	}
+	return res
+}
+
+func TestReplayGameState(t *testing.T) {
+	tests := []struct {
+		name     string
+		game     googs.Game
+		want     [][]int
+		wantLast googs.OriginCoordinate
+	}{
+		{
+			name: "no moves",
+			game: googs.Game{Width: 7, Height: 7},
+			want: parseBoard(
+				".......",
+				".......",
+				".......",
+				".......",
+				".......",
+				".......",
+				".......",
+			),
+			wantLast: googs.OriginCoordinate{X: -1, Y: -1},
+		},
+		{
+			name: "alternating moves with a pass",
+			game: googs.Game{Width: 7, Height: 7, Moves: moves([2]int{0, 0}, [2]int{1, 0}, [2]int{-1, -1}, [2]int{2, 0})},
+			want: parseBoard(
+				"XOO....",
+				".......",
+				".......",
+				".......",
+				".......",
+				".......",
+				".......",
+			),
+			wantLast: googs.OriginCoordinate{X: 2, Y: 0},
+		},
+		{
+			name: "corner capture",
+			game: googs.Game{Width: 7, Height: 7, Moves: moves([2]int{0, 0}, [2]int{1, 0}, [2]int{6, 6}, [2]int{0, 1})},
+			want: parseBoard(
+				".O.....",
+				"O......",
+				".......",
+				".......",
+				".......",
+				".......",
+				"......X",
+			),
+			wantLast: googs.OriginCoordinate{X: 0, Y: 1},
+		},
+		{
+			name: "free handicap placed by black in a row",
+			game: googs.Game{Width: 7, Height: 7, Handicap: 2, InitialPlayer: "black", Moves: moves([2]int{2, 2}, [2]int{4, 4}, [2]int{3, 3})},
+			want: parseBoard(
+				".......",
+				".......",
+				"..X....",
+				"...O...",
+				"....X..",
+				".......",
+				".......",
+			),
+			wantLast: googs.OriginCoordinate{X: 3, Y: 3},
+		},
+		{
+			name: "fixed handicap with white to move first",
+			game: googs.Game{Width: 9, Height: 9, Handicap: 3, InitialPlayer: "white", Moves: moves([2]int{4, 4}, [2]int{2, 4})},
+			want: parseBoard(
+				".........",
+				".........",
+				"......X..",
+				".........",
+				"..X.O....",
+				".........",
+				"..X...X..",
+				".........",
+				".........",
+			),
+			wantLast: googs.OriginCoordinate{X: 2, Y: 4},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			state := replayGameState(&tc.game)
+			if got, want := formatBoard(state.Board), formatBoard(tc.want); got != want {
+				t.Errorf("board\n%s\nwant\n%s", got, want)
+			}
+			if state.MoveNumber != len(tc.game.Moves) {
+				t.Errorf("move number %d, want %d", state.MoveNumber, len(tc.game.Moves))
+			}
+			if state.LastMove != tc.wantLast {
+				t.Errorf("last move %v, want %v", state.LastMove, tc.wantLast)
+			}
+		})
+	}
+}
+
+func TestHandicapPoints(t *testing.T) {
+	tests := []struct {
+		size, handicap int
+		want           []googs.OriginCoordinate
+	}{
+		{19, 1, nil},
+		{5, 2, nil},
+		{19, 10, nil},
+		{19, 2, []googs.OriginCoordinate{{X: 15, Y: 3}, {X: 3, Y: 15}}},
+		{13, 4, []googs.OriginCoordinate{{X: 9, Y: 3}, {X: 3, Y: 9}, {X: 9, Y: 9}, {X: 3, Y: 3}}},
+		{9, 5, []googs.OriginCoordinate{{X: 6, Y: 2}, {X: 2, Y: 6}, {X: 6, Y: 6}, {X: 2, Y: 2}, {X: 4, Y: 4}}},
+		{19, 6, []googs.OriginCoordinate{{X: 15, Y: 3}, {X: 3, Y: 15}, {X: 15, Y: 15}, {X: 3, Y: 3}, {X: 3, Y: 9}, {X: 15, Y: 9}}},
+		{19, 9, []googs.OriginCoordinate{
+			{X: 15, Y: 3}, {X: 3, Y: 15}, {X: 15, Y: 15}, {X: 3, Y: 3},
+			{X: 3, Y: 9}, {X: 15, Y: 9}, {X: 9, Y: 3}, {X: 9, Y: 15}, {X: 9, Y: 9},
+		}},
+	}
+	for _, tc := range tests {
+		got := handicapPoints(tc.size, tc.handicap)
+		if len(got) != len(tc.want) {
+			t.Errorf("handicapPoints(%d, %d) = %v, want %v", tc.size, tc.handicap, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("handicapPoints(%d, %d) = %v, want %v", tc.size, tc.handicap, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestEstimateTerritory(t *testing.T) {
+	// Black and white walls, a dead white stone in black's area marked by "o"
+	board := []string{
+		"o.XO.",
+		"..XO.",
+		"..XO.",
+		"..XO.",
+		"..XO.",
+	}
+	tests := []struct {
+		name    string
+		phase   googs.GamePhase
+		outcome string
+		want    []string // Owner of each point, "X", "O" or "."
+	}{
+		{
+			name:  "playing",
+			phase: googs.PlayPhase,
+			want:  []string{".....", ".....", ".....", ".....", "....."},
+		},
+		{
+			name:    "finished by resignation",
+			phase:   googs.FinishedPhase,
+			outcome: "Resignation",
+			want:    []string{".....", ".....", ".....", ".....", "....."},
+		},
+		{
+			name:  "stone removal",
+			phase: googs.StoneRemovalPhase,
+			want:  []string{"XX..O", "XX..O", "XX..O", "XX..O", "XX..O"},
+		},
+		{
+			name:    "finished by points",
+			phase:   googs.FinishedPhase,
+			outcome: "3.5 points",
+			want:    []string{"XX..O", "XX..O", "XX..O", "XX..O", "XX..O"},
+		},
+	}
+	state := &googs.GameState{Removal: make([][]int, len(board))}
+	for y, row := range board {
+		state.Removal[y] = make([]int, len(row))
+		if i := strings.IndexByte(row, 'o'); i >= 0 {
+			state.Removal[y][i] = 1
+		}
+		board[y] = strings.ReplaceAll(row, "o", "O")
+	}
+	state.Board = parseBoard(board...)
+
+	for _, tc := range tests {
+		state.Phase, state.Outcome = tc.phase, tc.outcome
+		territory := estimateTerritory(state)
+		var got [][]int
+		for _, row := range territory {
+			var r []int
+			for _, s := range row {
+				r = append(r, int(s))
+			}
+			got = append(got, r)
+		}
+		if got, want := formatBoard(got), formatBoard(parseBoard(tc.want...)); got != want {
+			t.Errorf("%s: territory\n%s\nwant\n%s", tc.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+var (
+	challengeSizes   = []int{19, 13, 9}
+	challengeRules   = []string{"japanese", "chinese", "aga", "korean", "nz", "ing"}
+	challengeColors  = []string{"automatic", "black", "white"}
+	challengeClocks  = []string{"byoyomi", "fischer", "canadian", "simple", "absolute", "none"}
+	challengeSpeeds  = []string{"correspondence", "live", "rapid", "blitz"}
+	challengeHandies = []string{"automatic", "0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+)
+
+type challengePage struct {
+	grid   *tview.Grid
+	form   *tview.Form
+	status *tview.TextView
+}
+
+func newChallengePage(app *App) Page {
+	p := &challengePage{
+		grid:   tview.NewGrid(),
+		form:   tview.NewForm(),
+		status: tview.NewTextView(),
+	}
+
+	// Settings of the form, updated by the form items
+	var (
+		size      = challengeSizes[0]
+		rules     = challengeRules[0]
+		color     = challengeColors[0]
+		clock     = challengeClocks[0]
+		speed     = challengeSpeeds[0]
+		handicap  = -1
+		ranked    = true
+		private   = false
+		opponent  string
+		name      = "Friendly Match"
+		mainTime  = "3d"
+		period    = "1d"
+		periods   = "5"
+		komi      string
+		sizeNames []string
+	)
+	for _, n := range challengeSizes {
+		sizeNames = append(sizeNames, fmt.Sprintf("%dx%d", n, n))
+	}
+
+	p.form.SetItemPadding(0).
+		AddInputField("Opponent", "", 24, nil, func(text string) { opponent = text }).
+		AddInputField("Game name", name, 24, nil, func(text string) { name = text }).
+		AddDropDown("Board size", sizeNames, 0, func(_ string, i int) { size = challengeSizes[i] }).
+		AddDropDown("Rules", challengeRules, 0, func(option string, _ int) { rules = option }).
+		AddDropDown("My color", challengeColors, 0, func(option string, _ int) { color = option }).
+		AddDropDown("Speed", challengeSpeeds, 0, func(option string, _ int) { speed = option }).
+		AddDropDown("Time control", challengeClocks, 0, func(option string, _ int) { clock = option }).
+		AddInputField("Main time", mainTime, 10, nil, func(text string) { mainTime = text }).
+		AddInputField("Period/Increment", period, 10, nil, func(text string) { period = text }).
+		AddInputField("Periods/Stones", periods, 10, nil, func(text string) { periods = text }).
+		AddDropDown("Handicap", challengeHandies, 0, func(option string, _ int) {
+			handicap, _ = strconv.Atoi(option) // 0 on error
+			handicap = util.Cond(option == "automatic", -1, handicap)
+		}).
+		AddInputField("Komi", "", 10, nil, func(text string) { komi = text }).
+		AddCheckbox("Ranked", ranked, func(checked bool) { ranked = checked }).
+		AddCheckbox("Private", private, func(checked bool) { private = checked })
+	p.form.GetFormItemByLabel("Opponent").(*tview.InputField).
+		SetPlaceholder("Username or ID, empty for open challenge").
+		SetPlaceholderTextColor(Styles.MoreContrastBackgroundColor)
+	p.form.GetFormItemByLabel("Komi").(*tview.InputField).
+		SetPlaceholder("Automatic").
+		SetPlaceholderTextColor(Styles.MoreContrastBackgroundColor)
+
+	p.form.SetButtonsAlign(tview.AlignCenter).
+		AddButton("Create", func() {
+			req := &ogs.ChallengeRequest{
+				MinRanking:      -1000,
+				MaxRanking:      1000,
+				ChallengerColor: color,
+				Game: ogs.ChallengeGame{
+					Name:            strings.TrimSpace(name),
+					Rules:           rules,
+					Ranked:          ranked,
+					Private:         private,
+					Width:           size,
+					Height:          size,
+					Handicap:        handicap,
+					KomiAuto:        "automatic",
+					PauseOnWeekends: speed == "correspondence",
+					TimeControl:     clock,
+				},
+			}
+			if req.Game.Name == "" {
+				p.setError(fmt.Errorf("game name is required"))
+				return
+			}
+			if komi = strings.TrimSpace(komi); komi != "" {
+				k, err := strconv.ParseFloat(komi, 32)
+				if err != nil {
+					p.setError(fmt.Errorf("invalid komi %q", komi))
+					return
+				}
+				custom := float32(k)
+				req.Game.KomiAuto = "custom"
+				req.Game.Komi = &custom
+			}
+			params, err := timeControlParameters(clock, speed, mainTime, period, periods)
+			if err != nil {
+				p.setError(err)
+				return
+			}
+			req.Game.TimeControlParameters = params
+
+			opponent := strings.TrimSpace(opponent)
+			p.status.SetText("Creating challenge ...")
+			go func() {
+				var playerID int64
+				if opponent != "" {
+					player, err := ogs.FindPlayer(app.client, opponent)
+					if err != nil {
+						app.redraw(func() { p.setError(err) })
+						return
+					}
+					playerID = player.ID
+				}
+				id, gameID, err := ogs.CreateChallenge(app.client, playerID, req)
+				if err != nil {
+					app.error("Create challenge %v", err)
+					app.redraw(func() { p.setError(err) })
+					return
+				}
+				app.info("Created challenge %d of game %d", id, gameID)
+				app.redraw(func() { app.switchToPage("home") })
+			}()
+		}).
+		AddButton("Cancel", func() { p.Leave(app) }).
+		SetTitle(" Create Challenge ").
+		SetBorder(true)
+
+	p.status.SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetTextColor(Styles.MoreContrastBackgroundColor)
+
+	// Center align the form and bottom status in a 3x3 grid
+	p.grid.SetRows(-1, p.form.GetFormItemCount()+5, 2, -1).
+		SetColumns(-1, 64, -1).
+		AddItem(p.form, 1, 1, 1, 1, 0, 0, true).
+		AddItem(p.status, 2, 1, 1, 1, 0, 0, false)
+	return p
+}
+
+func (p *challengePage) Root() tview.Primitive {
+	return p.grid
+}
+
+func (p *challengePage) Focusables() []tview.Primitive {
+	return []tview.Primitive{p.form}
+}
+
+func (p *challengePage) Refresh(app *App) error {
+	return nil
+}
+
+func (p *challengePage) Render(app *App) {
+	p.status.SetText("Times are like 30s, 10m, 1h or 3d")
+}
+
+func (p *challengePage) Leave(app *App) {
+	app.switchToPage("home")
+}
+
+func (p *challengePage) setError(err error) {
+	p.status.SetText(fmt.Sprintf("[red]%v[-]", err))
+}
+
+// Parse time like "30s", "10m", "1h30m" or "3d" into seconds
+func parseSeconds(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		return n * 86400, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return int(d.Seconds()), nil
+}
+
+// Build OGS time control parameters, main time is also the initial time,
+// total time or per move time depending on the system, period is also the
+// increment, and periods also the stones per period.
+func timeControlParameters(system, speed, mainTime, period, periods string) (map[string]any, error) {
+	params := map[string]any{
+		"system":            system,
+		"time_control":      system,
+		"speed":             speed,
+		"pause_on_weekends": speed == "correspondence",
+	}
+	if system == "none" {
+		return params, nil
+	}
+
+	main, err := parseSeconds(mainTime)
+	if err != nil {
+		return nil, err
+	}
+	var per, count int
+	if system == "byoyomi" || system == "fischer" || system == "canadian" {
+		if per, err = parseSeconds(period); err != nil {
+			return nil, err
+		}
+	}
+	if system == "byoyomi" || system == "canadian" {
+		if count, err = strconv.Atoi(strings.TrimSpace(periods)); err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid periods or stones %q", periods)
+		}
+	}
+
+	switch system {
+	case "byoyomi":
+		params["main_time"] = main
+		params["period_time"] = per
+		params["periods"] = count
+	case "fischer":
+		params["initial_time"] = main
+		params["time_increment"] = per
+		params["max_time"] = main
+	case "canadian":
+		params["main_time"] = main
+		params["period_time"] = per
+		params["stones_per_period"] = count
+	case "simple":
+		params["per_move"] = main
+	case "absolute":
+		params["total_time"] = main
+	}
+	return params, nil
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSeconds(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"30s", 30, false},
+		{"10m", 600, false},
+		{"1h30m", 5400, false},
+		{" 3d ", 259200, false},
+		{"1.5s", 1, false},
+		{"0s", 0, true},
+		{"-1m", 0, true},
+		{"0d", 0, true},
+		{"xd", 0, true},
+		{"10", 0, true},
+		{"", 0, true},
+	}
+	for _, tc := range tests {
+		got, err := parseSeconds(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseSeconds(%q) error %v, want error %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseSeconds(%q) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTimeControlParameters(t *testing.T) {
+	tests := []struct {
+		name                            string
+		system, speed, main, per, count string
+		want                            map[string]any
+		wantErr                         bool
+	}{
+		{
+			name: "none", system: "none", speed: "live",
+			want: map[string]any{"system": "none", "time_control": "none", "speed": "live", "pause_on_weekends": false},
+		},
+		{
+			name: "byoyomi", system: "byoyomi", speed: "live", main: "10m", per: "30s", count: "5",
+			want: map[string]any{"system": "byoyomi", "time_control": "byoyomi", "speed": "live", "pause_on_weekends": false,
+				"main_time": 600, "period_time": 30, "periods": 5},
+		},
+		{
+			name: "fischer", system: "fischer", speed: "correspondence", main: "3d", per: "1d",
+			want: map[string]any{"system": "fischer", "time_control": "fischer", "speed": "correspondence", "pause_on_weekends": true,
+				"initial_time": 259200, "time_increment": 86400, "max_time": 259200},
+		},
+		{
+			name: "canadian", system: "canadian", speed: "live", main: "20m", per: "5m", count: "25",
+			want: map[string]any{"system": "canadian", "time_control": "canadian", "speed": "live", "pause_on_weekends": false,
+				"main_time": 1200, "period_time": 300, "stones_per_period": 25},
+		},
+		{
+			name: "rapid", system: "fischer", speed: "rapid", main: "5m", per: "10s",
+			want: map[string]any{"system": "fischer", "time_control": "fischer", "speed": "rapid", "pause_on_weekends": false,
+				"initial_time": 300, "time_increment": 10, "max_time": 300},
+		},
+		{
+			name: "simple", system: "simple", speed: "blitz", main: "5s",
+			want: map[string]any{"system": "simple", "time_control": "simple", "speed": "blitz", "pause_on_weekends": false,
+				"per_move": 5},
+		},
+		{
+			name: "absolute", system: "absolute", speed: "live", main: "1h",
+			want: map[string]any{"system": "absolute", "time_control": "absolute", "speed": "live", "pause_on_weekends": false,
+				"total_time": 3600},
+		},
+		{name: "invalid main time", system: "absolute", speed: "live", main: "soon", wantErr: true},
+		{name: "invalid period", system: "fischer", speed: "live", main: "10m", per: "", wantErr: true},
+		{name: "invalid periods", system: "byoyomi", speed: "live", main: "10m", per: "30s", count: "0", wantErr: true},
+		{name: "invalid stones", system: "canadian", speed: "live", main: "10m", per: "5m", count: "many", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := timeControlParameters(tc.system, tc.speed, tc.main, tc.per, tc.count)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: error %v, want error %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
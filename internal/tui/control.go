@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ymattw/googs"
+)
+
+// How long a control command waits for the UI to pick it up
+const controlTimeout = 5 * time.Second
+
+// Pages reachable by the "page" control command
+var controlPages = []string{"home", "watch", "history", "challenge", "seek"}
+
+const controlHelp = "commands: state, waiting, page home|watch|history|challenge|seek, open <game id>, move <coordinate>, pass, chat <message>, scheme <name>"
+
+// Reply of the "state" command
+type controlState struct {
+	Username   string `json:"username"`
+	Page       string `json:"page"`
+	GameID     int64  `json:"game_id,omitempty"` // Game page in front, if any
+	NextBoards int    `json:"next_boards"`
+	LatencyMs  int64  `json:"latency_ms"`
+	DriftMs    int64  `json:"drift_ms"`
+}
+
+// Listen on the control socket so that other programs (editor plugins, tmux
+// bindings, status bars) can drive the app with a line based text protocol.
+// Each command gets one line of reply, either the result, "ok" or
+// "error: <reason>".
+func (app *App) startControl() {
+	path := app.cfg.Server.ControlSocketPath(app.client.Username)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		app.warn("Control socket %v", err)
+		return
+	}
+	// Take over a stale socket, but not one of a running instance
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		app.warn("Control socket %s in use by another instance", path)
+		return
+	}
+	os.Remove(path)
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		app.warn("Control socket %v", err)
+		return
+	}
+	app.control = l
+	app.info("Control socket listening on %s", path)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if err != nil {
+				app.warn("Control socket accept %v", err)
+				continue
+			}
+			go app.serveControl(conn)
+		}
+	}()
+}
+
+func (app *App) stopControl() {
+	if app.control != nil {
+		app.control.Close() // Also removes the socket file
+		app.control = nil
+	}
+}
+
+func (app *App) serveControl(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		reply, err := app.controlCommand(line)
+		if err != nil {
+			reply = "error: " + err.Error()
+		}
+		if _, err := fmt.Fprintln(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (app *App) controlCommand(line string) (string, error) {
+	cmd, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	app.debug("Control command %q", line)
+
+	switch cmd {
+	case "help":
+		return controlHelp, nil
+	case "state":
+		var s controlState
+		err := app.onUI(func() error {
+			front, _ := app.root.GetFrontPage()
+			s = controlState{
+				Username:   app.client.Username,
+				Page:       front,
+				NextBoards: len(app.nextBoard),
+				LatencyMs:  atomic.LoadInt64(&app.latency),
+				DriftMs:    atomic.LoadInt64(&app.drift),
+			}
+			if p := app.frontGamePage(); p != nil {
+				s.GameID = p.game.GameID
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		data, err := json.Marshal(s)
+		return string(data), err
+	case "waiting":
+		var n int
+		err := app.onUI(func() error {
+			n = len(app.nextBoard)
+			return nil
+		})
+		return strconv.Itoa(n), err
+	case "page":
+		if !oneOf(arg, controlPages...) {
+			return "", fmt.Errorf("unknown page %q", arg)
+		}
+		return "ok", app.onUI(func() error {
+			if p := app.frontGamePage(); p != nil {
+				p.close(app)
+			}
+			app.switchToPage(arg)
+			return nil
+		})
+	case "open":
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			return "", fmt.Errorf("invalid game id %q", arg)
+		}
+		return "ok", app.onUI(func() error {
+			returnPage := ""
+			if p := app.frontGamePage(); p != nil {
+				if p.game.GameID == id {
+					return nil
+				}
+				returnPage = p.returnPage
+				p.close(app)
+			}
+			app.switchToNewGamePage(id, returnPage)
+			return nil
+		})
+	case "scheme":
+		if _, ok := uiSchemes[arg]; !ok {
+			return "", fmt.Errorf("unknown scheme %q, one of %s", arg, strings.Join(uiSchemeNames(), ", "))
+		}
+		return "ok", app.onUI(func() error {
+			app.setUIScheme(arg)
+			return nil
+		})
+	case "move":
+		a1, err := googs.NewA1Coordinate(arg)
+		if err != nil {
+			return "", err
+		}
+		// Check on the UI goroutine, send from here
+		var gameID int64
+		var o *googs.OriginCoordinate
+		err = app.onGamePage(func(p *gamePage) error {
+			gameID = p.game.GameID
+			if !p.gameState.IsMyTurn(app.client.UserID) {
+				return fmt.Errorf("not your turn in game %d", gameID)
+			}
+			if o, err = a1.ToOriginCoordinate(p.gameState.BoardSize()); err != nil {
+				return err
+			}
+			if p.gameState.Board[o.Y][o.X] != 0 {
+				return fmt.Errorf("%s is occupied", arg)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return "ok", app.client.GameMove(gameID, o.X, o.Y)
+	case "pass":
+		var gameID int64
+		err := app.onGamePage(func(p *gamePage) error {
+			gameID = p.game.GameID
+			if !p.gameState.IsMyTurn(app.client.UserID) {
+				return fmt.Errorf("not your turn in game %d", gameID)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return "ok", app.client.PassTurn(gameID)
+	case "chat":
+		if arg == "" {
+			return "", fmt.Errorf("empty message")
+		}
+		var gameID int64
+		var moveNumber int
+		err := app.onGamePage(func(p *gamePage) error {
+			gameID, moveNumber = p.game.GameID, p.gameState.MoveNumber
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return "ok", app.client.GameChat(gameID, moveNumber, arg)
+	}
+	return "", fmt.Errorf("unknown command %q, try help", cmd)
+}
+
+// Run fn in the UI goroutine and wait for its result
+func (app *App) onUI(fn func() error) error {
+	done := make(chan error, 1)
+	go app.tui.QueueUpdateDraw(func() { done <- fn() })
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(controlTimeout):
+		return fmt.Errorf("app not responding")
+	}
+}
+
+// Run fn on the game page in front in the UI goroutine
+func (app *App) onGamePage(fn func(*gamePage) error) error {
+	return app.onUI(func() error {
+		p := app.frontGamePage()
+		if p == nil || p.gameState == nil {
+			return fmt.Errorf("no game open")
+		}
+		return fn(p)
+	})
+}
+
+// The game page in front, nil if not on a game page
+func (app *App) frontGamePage() *gamePage {
+	front, _ := app.root.GetFrontPage()
+	p, _ := app.pages[front].(*gamePage)
+	return p
+}
@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func TestControlCommandArgs(t *testing.T) {
+	app := &App{logger: tview.NewTextView()}
+	for _, line := range []string{
+		"page",
+		"page game",
+		"page Home",
+		"open",
+		"open 0",
+		"open -1",
+		"open 12x",
+		"move",
+		"move I4",
+		"move D0",
+		"move D26",
+		"move D",
+		"move 4D",
+		"move pass",
+		"chat",
+		"scheme none",
+		"resign",
+	} {
+		if got, err := app.controlCommand(line); err == nil {
+			t.Errorf("controlCommand(%q) = %q, want error", line, got)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+var (
+	// Empty sort key means server order
+	homeSortKeys = []string{"", "clock", "moves", "rank", "size", "turn"}
+
+	filterSizes  = []string{"", "9x9", "13x13", "19x19", "other"}
+	filterRanked = []string{"", "ranked", "unranked"}
+	filterSpeeds = []string{"", "blitz", "rapid", "live", "correspondence"}
+	filterBots   = []string{"", "bots", "humans"}
+)
+
+// Fields of the filter form
+const (
+	filterFieldMyTurn = "my turn"
+	filterFieldSize   = "size"
+	filterFieldRanked = "ranked"
+	filterFieldSpeed  = "speed"
+	filterFieldBots   = "bots"
+	filterFieldRank   = "rank"
+)
+
+// Return the sort key next to the current
+func nextSortKey(keys []string, current string) string {
+	for i, k := range keys {
+		if k == current {
+			return keys[(i+1)%len(keys)]
+		}
+	}
+	return keys[0]
+}
+
+// Sort games by given key, the natural order puts the most urgent or
+// interesting games on top.
+func sortGames(games []*googs.GameOverview, key string, reverse bool, myUserID int64) {
+	less := map[string]func(a, b *googs.GameOverview) bool{
+		"clock": func(a, b *googs.GameOverview) bool {
+			return ogs.GameTimeLeft(&a.Game) < ogs.GameTimeLeft(&b.Game)
+		},
+		"moves": func(a, b *googs.GameOverview) bool {
+			return len(a.Moves) < len(b.Moves)
+		},
+		"rank": func(a, b *googs.GameOverview) bool {
+			return a.Opponent(myUserID).Rank > b.Opponent(myUserID).Rank
+		},
+		"size": func(a, b *googs.GameOverview) bool {
+			return a.BoardSize() < b.BoardSize()
+		},
+		"turn": func(a, b *googs.GameOverview) bool {
+			return a.IsMyTurn(myUserID) && !b.IsMyTurn(myUserID)
+		},
+	}[key]
+	if less == nil {
+		if reverse {
+			for i, j := 0, len(games)-1; i < j; i, j = i+1, j-1 {
+				games[i], games[j] = games[j], games[i]
+			}
+		}
+		return
+	}
+	sort.SliceStable(games, func(i, j int) bool {
+		if reverse {
+			return less(games[j], games[i])
+		}
+		return less(games[i], games[j])
+	})
+}
+
+func sizeName(size int) string {
+	if size == 9 || size == 13 || size == 19 {
+		return fmt.Sprintf("%dx%d", size, size)
+	}
+	return "other"
+}
+
+func matchGame(f *config.GameFilter, g *googs.Game, myUserID int64) bool {
+	if f.MyTurn && !g.IsMyTurn(myUserID) {
+		return false
+	}
+	if f.Size != "" && f.Size != sizeName(g.BoardSize()) {
+		return false
+	}
+	if f.Ranked != "" && f.Ranked != util.Cond(g.Ranked, "ranked", "unranked") {
+		return false
+	}
+	if f.Speed != "" && f.Speed != g.TimeControl.Speed {
+		return false
+	}
+	return true
+}
+
+// Speed of a listed game by average seconds per move, roughly as classified
+// by OGS
+func speedOf(secondsPerMove int64) string {
+	switch {
+	case secondsPerMove <= 0 || secondsPerMove > 3600:
+		return "correspondence"
+	case secondsPerMove < 10:
+		return "blitz"
+	case secondsPerMove < 30:
+		return "rapid"
+	}
+	return "live"
+}
+
+// Parse rank like "25k" or "3d" into OGS ranking, where 1k is 29 and 1d is 30
+func parseRank(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var n int
+	var unit string
+	if _, err := fmt.Sscanf(s, "%d%s", &n, &unit); err != nil {
+		return 0, fmt.Errorf("invalid rank %q, expect e.g. 25k or 3d", s)
+	}
+	switch {
+	case unit == "k" && n >= 1 && n <= 30:
+		return float64(30 - n), nil
+	case unit == "d" && n >= 1 && n <= 9:
+		return float64(29 + n), nil
+	}
+	return 0, fmt.Errorf("invalid rank %q, expect 30k-1k or 1d-9d", s)
+}
+
+// Whether the player's rank is within the filter's rank range
+func matchRank(f *config.GameFilter, p *googs.Player) bool {
+	rank := math.Floor(float64(p.Rank))
+	if min, err := parseRank(f.MinRank); err == nil && rank < min {
+		return false
+	}
+	if max, err := parseRank(f.MaxRank); err == nil && rank > max {
+		return false
+	}
+	return true
+}
+
+// Build the query condition for what the server is able to filter
+func gameListWhere(f *config.GameFilter) *googs.GameListWhere {
+	where := &googs.GameListWhere{
+		HideRanked:   f.Ranked == "unranked",
+		HideUnranked: f.Ranked == "ranked",
+		HideBotGames: f.Bots == "humans",
+	}
+	if f.Size != "" {
+		where.Hide9x9 = f.Size != "9x9"
+		where.Hide13x13 = f.Size != "13x13"
+		where.Hide19x19 = f.Size != "19x19"
+		where.HideOther = f.Size != "other"
+	}
+	return where
+}
+
+// Match what the server is unable to filter, both players must be within the
+// rank range.
+func matchListEntry(f *config.GameFilter, g *googs.GameListEntry) bool {
+	if f.Speed != "" && f.Speed != speedOf(g.SecondsPerMove) {
+		return false
+	}
+	if f.Bots == "bots" && !g.BotGame {
+		return false
+	}
+	return matchRank(f, &g.Black) && matchRank(f, &g.White)
+}
+
+// Describe the filter in short, empty for no filtering
+func filterString(f *config.GameFilter) string {
+	var parts []string
+	if f.MyTurn {
+		parts = append(parts, "my turn")
+	}
+	for _, s := range []string{f.Size, f.Ranked, f.Speed, f.Bots} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if f.MinRank != "" || f.MaxRank != "" {
+		parts = append(parts, fmt.Sprintf("%s-%s", f.MinRank, f.MaxRank))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Describe the part of the filter matchListEntry() applies, which the server
+// is unable to filter, empty for none
+func localFilterString(f *config.GameFilter) string {
+	var parts []string
+	if f.Speed != "" {
+		parts = append(parts, f.Speed)
+	}
+	if f.Bots == "bots" {
+		parts = append(parts, "bots")
+	}
+	if f.MinRank != "" || f.MaxRank != "" {
+		parts = append(parts, fmt.Sprintf("%s-%s", f.MinRank, f.MaxRank))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Labels of options where the first one is empty for any
+func anyOf(options []string) []string {
+	return append([]string{"any"}, options[1:]...)
+}
+
+func indexOf(options []string, s string) int {
+	for i, o := range options {
+		if o == s {
+			return i
+		}
+	}
+	return 0
+}
+
+// Pop up a form to edit the filter in place with given fields, callback is
+// called when applied.
+func (app *App) editFilter(f *config.GameFilter, fields []string, callback func()) {
+	returnPage, _ := app.root.GetFrontPage()
+	returnFocus := app.tui.GetFocus()
+	pageName := returnPage + "-filter"
+	dismiss := func() {
+		app.root.RemovePage(pageName)
+		app.root.SwitchToPage(returnPage)
+		app.tui.SetFocus(returnFocus)
+	}
+
+	edited := *f
+	status := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	form := tview.NewForm()
+	for _, field := range fields {
+		switch field {
+		case filterFieldMyTurn:
+			form.AddCheckbox("My turn only", edited.MyTurn, func(checked bool) {
+				edited.MyTurn = checked
+			})
+		case filterFieldSize:
+			form.AddDropDown("Size", anyOf(filterSizes), indexOf(filterSizes, edited.Size), func(_ string, i int) {
+				edited.Size = filterSizes[i]
+			})
+		case filterFieldRanked:
+			form.AddDropDown("Ranked", anyOf(filterRanked), indexOf(filterRanked, edited.Ranked), func(_ string, i int) {
+				edited.Ranked = filterRanked[i]
+			})
+		case filterFieldSpeed:
+			form.AddDropDown("Speed", anyOf(filterSpeeds), indexOf(filterSpeeds, edited.Speed), func(_ string, i int) {
+				edited.Speed = filterSpeeds[i]
+			})
+		case filterFieldBots:
+			form.AddDropDown("Bots", anyOf(filterBots), indexOf(filterBots, edited.Bots), func(_ string, i int) {
+				edited.Bots = filterBots[i]
+			})
+		case filterFieldRank:
+			form.AddInputField("Min rank", edited.MinRank, 6, nil, func(text string) {
+				edited.MinRank = strings.TrimSpace(text)
+			})
+			form.AddInputField("Max rank", edited.MaxRank, 6, nil, func(text string) {
+				edited.MaxRank = strings.TrimSpace(text)
+			})
+		}
+	}
+	form.SetButtonsAlign(tview.AlignCenter).
+		AddButton("Apply", func() {
+			for _, r := range []string{edited.MinRank, edited.MaxRank} {
+				if _, err := parseRank(r); r != "" && err != nil {
+					status.SetText(fmt.Sprintf("[red]%v[-]", err))
+					return
+				}
+			}
+			*f = edited
+			dismiss()
+			callback()
+		}).
+		AddButton("Reset", func() {
+			*f = config.GameFilter{}
+			dismiss()
+			callback()
+		}).
+		AddButton("Cancel", dismiss).
+		SetCancelFunc(dismiss).
+		SetTitle(" Filter games ").
+		SetBorder(true)
+
+	// Center align the form and bottom status in a 4x3 grid
+	grid := tview.NewGrid().
+		SetRows(-1, form.GetFormItemCount()*2+5, 1, -1).
+		SetColumns(-1, 48, -1).
+		AddItem(form, 1, 1, 1, 1, 0, 0, true).
+		AddItem(status, 2, 1, 1, 1, 0, 0, false)
+	app.root.AddPage(pageName, grid, true, true)
+	app.tui.SetFocus(form)
+}
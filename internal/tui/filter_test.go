@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+)
+
+func TestParseRank(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"30k", 0, false},
+		{"25k", 5, false},
+		{"1k", 29, false},
+		{"1d", 30, false},
+		{" 3D ", 32, false},
+		{"9d", 38, false},
+		{"0k", 0, true},
+		{"31k", 0, true},
+		{"10d", 0, true},
+		{"5p", 0, true},
+		{"k", 0, true},
+		{"", 0, true},
+	}
+	for _, tc := range tests {
+		got, err := parseRank(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseRank(%q) error %v, want error %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseRank(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestMatchGame(t *testing.T) {
+	const me = 100
+	game := googs.Game{
+		Width:       19,
+		Height:      19,
+		Ranked:      true,
+		Clock:       googs.Clock{CurrentPlayerID: me},
+		TimeControl: googs.TimeControl{Speed: "correspondence"},
+	}
+	tests := []struct {
+		name   string
+		filter config.GameFilter
+		want   bool
+	}{
+		{"no filter", config.GameFilter{}, true},
+		{"my turn", config.GameFilter{MyTurn: true}, true},
+		{"size", config.GameFilter{Size: "19x19"}, true},
+		{"other size", config.GameFilter{Size: "9x9"}, false},
+		{"ranked", config.GameFilter{Ranked: "ranked"}, true},
+		{"unranked", config.GameFilter{Ranked: "unranked"}, false},
+		{"speed", config.GameFilter{Speed: "correspondence"}, true},
+		{"other speed", config.GameFilter{Speed: "blitz"}, false},
+		{"all match", config.GameFilter{MyTurn: true, Size: "19x19", Ranked: "ranked", Speed: "correspondence"}, true},
+	}
+	for _, tc := range tests {
+		if got := matchGame(&tc.filter, &game, me); got != tc.want {
+			t.Errorf("%s: matchGame() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	theirTurn := game
+	theirTurn.Clock.CurrentPlayerID = me + 1
+	if matchGame(&config.GameFilter{MyTurn: true}, &theirTurn, me) {
+		t.Errorf("matchGame() matched my turn on opponent's turn")
+	}
+}
+
+func TestMatchListEntry(t *testing.T) {
+	entry := googs.GameListEntry{
+		Black:          googs.Player{Rank: 20.5}, // 10k
+		White:          googs.Player{Rank: 25},   // 5k
+		SecondsPerMove: 20,
+	}
+	tests := []struct {
+		name   string
+		filter config.GameFilter
+		want   bool
+	}{
+		{"no filter", config.GameFilter{}, true},
+		{"speed", config.GameFilter{Speed: "rapid"}, true},
+		{"other speed", config.GameFilter{Speed: "live"}, false},
+		{"bots only", config.GameFilter{Bots: "bots"}, false},
+		{"rank range", config.GameFilter{MinRank: "12k", MaxRank: "5k"}, true},
+		{"min rank boundary", config.GameFilter{MinRank: "10k"}, true},
+		{"min rank above weaker player", config.GameFilter{MinRank: "9k"}, false},
+		{"max rank below stronger player", config.GameFilter{MaxRank: "6k"}, false},
+		{"invalid rank ignored", config.GameFilter{MinRank: "bogus"}, true},
+	}
+	for _, tc := range tests {
+		if got := matchListEntry(&tc.filter, &entry); got != tc.want {
+			t.Errorf("%s: matchListEntry() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLocalFilterString(t *testing.T) {
+	tests := []struct {
+		filter config.GameFilter
+		want   string
+	}{
+		{config.GameFilter{}, ""},
+		{config.GameFilter{Size: "9x9", Ranked: "ranked", Bots: "humans"}, ""},
+		{config.GameFilter{Speed: "blitz", Bots: "bots"}, "blitz, bots"},
+		{config.GameFilter{MinRank: "5k"}, "5k-"},
+	}
+	for _, tc := range tests {
+		if got := localFilterString(&tc.filter); got != tc.want {
+			t.Errorf("localFilterString(%+v) = %q, want %q", tc.filter, got, tc.want)
+		}
+	}
+}
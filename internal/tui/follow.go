@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/ogs"
+)
+
+// Pseudo list type for games of followed players on the watch page
+const followingList googs.GameListType = "following"
+
+const followListPageSize = 50
+
+// Copy of the follow list, safe to call from any goroutine
+func (app *App) following() []config.Followed {
+	app.prefsLock.Lock()
+	defer app.prefsLock.Unlock()
+	return append([]config.Followed(nil), app.prefs.Following...)
+}
+
+func (app *App) followedIDs() []int64 {
+	var ids []int64
+	for _, f := range app.following() {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+// Usernames of followed players in the game
+func (app *App) followedIn(g *googs.GameListEntry) []string {
+	var names []string
+	for _, f := range app.following() {
+		if f.ID == g.Black.ID || f.ID == g.White.ID {
+			names = append(names, f.Username)
+		}
+	}
+	return names
+}
+
+// All live and correspondence games of followed players, the where condition
+// is optional. An empty list is returned without querying when nobody is
+// followed, as no player condition means all games. Lists are paged through
+// so that polls compare complete snapshots.
+func (app *App) followedGames(where *googs.GameListWhere) (*googs.GameListResponse, error) {
+	res := &googs.GameListResponse{List: followingList}
+	ids := app.followedIDs()
+	if len(ids) == 0 {
+		return res, nil
+	}
+	if where == nil {
+		where = &googs.GameListWhere{}
+	}
+	where.PlayerIDs = ids
+	for _, list := range []googs.GameListType{googs.LiveGameList, googs.CorrespondenceGameList} {
+		for from := 0; ; {
+			resp, err := app.client.GameListQuery(list, from, followListPageSize, where, 10*time.Second)
+			if err != nil {
+				return nil, err
+			}
+			res.Results = append(res.Results, resp.Results...)
+			from += len(resp.Results)
+			if len(resp.Results) == 0 || from >= resp.Size {
+				break
+			}
+		}
+	}
+	res.Size = len(res.Results)
+	return res, nil
+}
+
+// Poll games of followed players and notify when they start or finish games,
+// runs until done is closed.
+func (app *App) pollFollowed(done <-chan struct{}) {
+	var key string // Followed players of last poll
+	var seen map[int64]googs.GameListEntry
+
+	poll := func() {
+		resp, err := app.followedGames(nil)
+		if err != nil {
+			app.warn("Poll games of followed players %v", err)
+			return
+		}
+		current := make(map[int64]googs.GameListEntry)
+		for _, g := range resp.Results {
+			current[g.ID] = g
+		}
+
+		// Start over silently when the followed players change
+		newKey := fmt.Sprint(app.followedIDs())
+		if seen != nil && newKey == key {
+			for id, g := range current {
+				if _, ok := seen[id]; !ok {
+					app.notify("%s started game #%d %s", strings.Join(app.followedIn(&g), ", "), id, trimString(g.Name, 20))
+				}
+			}
+			for id, g := range seen {
+				if _, ok := current[id]; !ok {
+					app.notify("%s finished game #%d %s", strings.Join(app.followedIn(&g), ", "), id, trimString(g.Name, 20))
+				}
+			}
+		}
+		key, seen = newKey, current
+	}
+
+	poll()
+	ticker := time.NewTicker(app.cfg.UI.Refresh.Following.Std())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			poll()
+		}
+	}
+}
+
+// Look up the player and add to the follow list. Must NOT be called from the
+// UI goroutine.
+func (app *App) follow(nameOrID string) error {
+	player, err := ogs.FindPlayer(app.client, nameOrID)
+	if err != nil {
+		return err
+	}
+	app.prefsLock.Lock()
+	for _, f := range app.prefs.Following {
+		if f.ID == player.ID {
+			app.prefsLock.Unlock()
+			return fmt.Errorf("already following %s", player.Username)
+		}
+	}
+	following := append(app.prefs.Following, config.Followed{ID: player.ID, Username: player.Username})
+	sort.Slice(following, func(i, j int) bool {
+		return strings.ToLower(following[i].Username) < strings.ToLower(following[j].Username)
+	})
+	app.prefs.Following = following
+	app.prefsLock.Unlock()
+
+	app.info("Following %s (%d)", player.Username, player.ID)
+	app.savePrefs()
+	return nil
+}
+
+func (app *App) unfollow(playerID int64) {
+	var following []config.Followed
+	app.prefsLock.Lock()
+	for _, f := range app.prefs.Following {
+		if f.ID != playerID {
+			following = append(following, f)
+		} else {
+			app.info("Unfollowed %s (%d)", f.Username, f.ID)
+		}
+	}
+	app.prefs.Following = following
+	app.prefsLock.Unlock()
+	app.savePrefs()
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
 )
 
 type gamePage struct {
@@ -34,9 +37,13 @@ type gamePage struct {
 	clock      *googs.Clock
 	boardTheme string
 	cursor     *googs.OriginCoordinate
-	ticker     *time.Ticker
+	done       chan struct{} // Closed to stop background work
 	chats      []*googs.GameChatLine
 	chatsLock  sync.Mutex
+	rematch    rematch
+	clockLow   time.Duration // Clocks under this shown in red, 0 for never
+	clockAlert bool          // Own clock low notified
+	chatHidden bool
 }
 
 func newGamePage(app *App, gameID int64, returnPage string) Page {
@@ -60,24 +67,35 @@ func newGamePage(app *App, gameID int64, returnPage string) Page {
 		game:       &googs.Game{},      // Avoid nil deference
 		gameState:  &googs.GameState{}, // Avoid nil deference
 		clock:      &googs.Clock{},     // avoid nil deference
-		boardTheme: "night",
+		boardTheme: app.boardTheme(gameID),
+		clockLow:   app.cfg.UI.Alerts.ClockLow.Std(),
+		chatHidden: app.cfg.UI.Layout.HideChat,
 		cursor:     &googs.OriginCoordinate{},
-		ticker:     time.NewTicker(time.Second),
+		done:       make(chan struct{}),
 	}
 
 	// Update Next label and clock displays every second, keep it simple
 	// instead of dynamically reset
-	go func() {
-		for range p.ticker.C {
-			updated := p.updatePlayers()
-			newLabel := fmt.Sprintf("Next (%d)", len(app.nextBoard))
-			if updated || newLabel != p.next.GetLabel() {
-				app.redraw(func() {
-					p.next.SetLabel(newLabel)
-				})
+	go func(done <-chan struct{}) {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				updated := p.updatePlayers()
+				p.alertClockLow(app)
+				newLabel := fmt.Sprintf("Next (%d)", len(app.nextBoard))
+				if updated || newLabel != p.next.GetLabel() {
+					app.redraw(func() {
+						p.next.SetLabel(newLabel)
+					})
+				}
+				p.pollRematch(app)
 			}
 		}
-	}()
+	}(p.done)
 
 	p.next.SetSelectedFunc(func() {
 		if g := app.nextGameEntry(); g != nil {
@@ -153,6 +171,9 @@ func (p *gamePage) Root() tview.Primitive {
 }
 
 func (p *gamePage) Focusables() []tview.Primitive {
+	if p.chatHidden {
+		return []tview.Primitive{p.board, p.next, p.home, p.watch, p.logout}
+	}
 	return []tview.Primitive{p.board, p.chat, p.message, p.next, p.home, p.watch, p.logout}
 }
 
@@ -172,9 +193,9 @@ func (p *gamePage) Refresh(app *App) error {
 	return nil
 }
 
-func (p *gamePage) resetLayout() {
+func (p *gamePage) resetLayout(app *App) {
 	navbar := tview.NewFlex().SetDirection(tview.FlexColumn).
-		AddItem(nil, 0, 1, false). // left spacer
+		AddItem(app.navStatus, 0, 1, false). // left spacer
 		AddItem(p.next, 10, 0, false).
 		AddItem(nil, 1, 0, false). // gap
 		AddItem(p.home, 10, 0, false).
@@ -193,17 +214,17 @@ func (p *gamePage) resetLayout() {
 
 	// Align the elements in a 11x7 grid
 	p.grid.SetRows(
-		1,                    // navbar
-		-1,                   // spacer
-		1,                    // title
-		-1,                   // spacer
-		p.game.BoardSize()+2, // board with labels
-		-1,                   // spacer
-		1,                    // status
-		1,                    // hint
-		-3,                   // chat
-		1,                    // message
-		-1,                   // spacer
+		1,                               // navbar
+		-1,                              // spacer
+		1,                               // title
+		-1,                              // spacer
+		p.game.BoardSize()+2,            // board with labels
+		-1,                              // spacer
+		1,                               // status
+		1,                               // hint
+		util.Cond(p.chatHidden, -1, -3), // chat, or spacer if hidden
+		1,                               // message
+		-1,                              // spacer
 	)
 	p.grid.SetColumns(
 		-1,                       // spacer
@@ -229,6 +250,9 @@ func (p *gamePage) resetLayout() {
 	p.grid.AddItem(p.status, 6, 1, 1, 5, 1, 0, false)
 	// Row 7: spacer, hint (5 columns), spacer
 	p.grid.AddItem(p.hint, 7, 1, 1, 5, 1, 0, false)
+	if p.chatHidden {
+		return
+	}
 	// Row 8: chat (7 columns)
 	p.grid.AddItem(p.chat, 8, 0, 1, 7, 1, 50, false)
 	// Row 9: message (7 columns)
@@ -252,15 +276,15 @@ func (p *gamePage) gameTitle() string {
 		"nz":       "NZ",
 	}[strings.ToLower(p.game.Rules)]
 
-	ranked := cond(p.game.Ranked, "ranked", "unranked")
-	private := cond(p.game.Private, "🔒", "")
+	ranked := util.Cond(p.game.Ranked, "ranked", "unranked")
+	private := util.Cond(p.game.Private, "🔒", "")
 	handicap := circledNumber(p.game.Handicap)
 	return fmt.Sprintf("%s | %s %s %s | %s +%.1f | %s %s",
 		trimString(p.game.GameName, 30), speed, rule, p.game.TimeControl, handicap, p.game.Komi, ranked, private)
 }
 
 func (p *gamePage) Render(app *App) {
-	p.resetLayout()
+	p.resetLayout(app)
 	p.setupKeys(app) // p.board is dynamical
 
 	p.title.SetText(p.gameTitle())
@@ -282,7 +306,10 @@ func (p *gamePage) Render(app *App) {
 		app.info("Game %d phase changed to %s", p.game.GameID, phase)
 		p.refreshGame(app)
 		p.refreshGameState(app) // gameState has removal and outcome
-		app.redraw(func() { p.updateStatusAndHint(app) })
+		app.redraw(func() {
+			p.updateStatusAndHint(app)
+			app.tvGamePhase(p.game.GameID, phase)
+		})
 	})
 
 	app.client.OnGameRemovedStones(p.game.GameID, func(r *googs.RemovedStones) {
@@ -296,15 +323,15 @@ func (p *gamePage) Render(app *App) {
 		app.info("Game %d stone removal accepted by %d", p.game.GameID, r.PlayerID)
 		var who string
 		if p.game.IsMyGame(app.client.UserID) {
-			who = cond(r.PlayerID == app.client.UserID, "You have", "Opponent has")
+			who = util.Cond(r.PlayerID == app.client.UserID, "You have", "Opponent has")
 		} else {
-			who = cond(r.PlayerID == p.game.BlackPlayerID, "Black has", "White has")
+			who = util.Cond(r.PlayerID == p.game.BlackPlayerID, "Black has", "White has")
 		}
 		app.redraw(func() {
 			p.status.SetText("[red]" + who + " accepted stone removal[-]")
 			if r.Phase == googs.FinishedPhase {
 				p.status.SetText("[green]" + r.Result() + "[-]")
-				p.hint.SetText(keyHints(nil))
+				p.hint.SetText(app.keyHints("game"))
 			}
 		})
 	})
@@ -346,7 +373,7 @@ func insertSortedChats(lines []*googs.GameChatLine, newLine *googs.GameChatLine)
 }
 
 func (p *gamePage) refreshGame(app *App) error {
-	g, err := app.client.Game(p.gameID)
+	g, err := ogs.Game(app.client, p.gameID)
 	if err != nil {
 		app.error("Refresh game %v", err)
 		return err
@@ -358,12 +385,13 @@ func (p *gamePage) refreshGame(app *App) error {
 func (p *gamePage) updatePlayer(t *tview.TextView, c googs.PlayerColor) bool {
 	// Use a blinking dot to indicate who is on turn
 	// TODO: online status
-	title := cond(p.game.WhoseTurn(p.gameState) == c,
+	title := util.Cond(p.game.WhoseTurn(p.gameState) == c,
 		fmt.Sprintf(" %s [::l]•[-] ", c),
 		fmt.Sprintf(" %s ", c))
 	clock := p.clock.ComputeClock(&p.game.TimeControl, c)
-	style := cond(clock != nil && clock.SuddenDeath, "[red]", "")
-	player := cond(c == googs.PlayerBlack, p.game.BlackPlayer(), p.game.WhitePlayer())
+	low := p.clockLow > 0 && ogs.TimeLeft(clock, &p.game.TimeControl) < p.clockLow
+	style := util.Cond(clock != nil && (clock.SuddenDeath || low), "[red]", "")
+	player := util.Cond(c == googs.PlayerBlack, p.game.BlackPlayer(), p.game.WhitePlayer())
 	text := fmt.Sprintf("\n%s\n\n%s%s[-]", player, style, clock)
 
 	if title == t.GetTitle() && text == t.GetText(false) {
@@ -374,6 +402,22 @@ func (p *gamePage) updatePlayer(t *tview.TextView, c googs.PlayerColor) bool {
 	return true
 }
 
+// Notify once when own clock runs low, again after it recovers (e.g. by time
+// increment)
+func (p *gamePage) alertClockLow(app *App) {
+	if p.clockLow == 0 || p.game.Phase != googs.PlayPhase || !p.game.IsMyGame(app.client.UserID) {
+		return
+	}
+	me := util.Cond(p.game.Players.Black.ID == app.client.UserID, googs.PlayerBlack, googs.PlayerWhite)
+	left := ogs.TimeLeft(p.clock.ComputeClock(&p.game.TimeControl, me), &p.game.TimeControl)
+	if left >= p.clockLow {
+		p.clockAlert = false
+	} else if !p.clockAlert {
+		p.clockAlert = true
+		app.notify("Clock low in game %d, %s left", p.game.GameID, left.Truncate(time.Second))
+	}
+}
+
 func (p *gamePage) updatePlayers() bool {
 	if p.game.Phase != googs.PlayPhase {
 		return false
@@ -388,19 +432,20 @@ func (p *gamePage) updateStatusAndHint(app *App) {
 	switch p.game.Phase {
 	case googs.PlayPhase:
 		p.status.SetText(p.game.Status(p.gameState, app.client.UserID))
-		p.hint.SetText(cond(p.gameState.IsMyTurn(app.client.UserID),
-			keyHints([]string{"←↓↑→hjkl move cursor", "CR play", "Pass", "Resign", "theme"}),
-			cond(isMyGame,
-				keyHints([]string{"Resign", "theme"}),
-				keyHints([]string{"theme"}))))
+		p.hint.SetText(util.Cond(p.gameState.IsMyTurn(app.client.UserID),
+			app.keyHints("game", "left/down/up/right", "play", "pass", "resign", "theme"),
+			util.Cond(isMyGame,
+				app.keyHints("game", "resign", "theme"),
+				app.keyHints("game", "theme"))))
 	case googs.StoneRemovalPhase:
 		p.status.SetText(fmt.Sprintf("%s phase", p.game.Phase))
-		p.hint.SetText(cond(isMyGame,
-			keyHints([]string{"Accept", "theme"}),
-			keyHints([]string{"theme"})))
+		p.hint.SetText(util.Cond(isMyGame,
+			app.keyHints("game", "accept", "theme"),
+			app.keyHints("game", "theme")))
 	case googs.FinishedPhase:
-		p.status.SetText("[green]" + p.game.Result() + "[-]")
-		p.hint.SetText(keyHints([]string{"theme"}))
+		rematchStatus, rematchHints := p.rematchStatusAndHints(app)
+		p.status.SetText("[green]" + p.game.Result() + "[-]" + rematchStatus)
+		p.hint.SetText(app.keyHints("game", append(rematchHints, "theme")...))
 	}
 }
 
@@ -417,7 +462,7 @@ func (p *gamePage) updateChatTable() {
 			Username:     line.Username,
 		}
 		p.chat.SetCell(row, 0, tview.NewTableCell(line.Date.Format("Jan 2 15:04:05")))
-		p.chat.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf("%d", line.MoveNumber)).SetTextColor(solarizedGreen).SetAlign(tview.AlignRight))
+		p.chat.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf("%d", line.MoveNumber)).SetTextColor(Colors.SuccessColor).SetAlign(tview.AlignRight))
 		p.chat.SetCell(row, 2, tview.NewTableCell(player.String()).SetTextColor(Styles.TertiaryTextColor))
 		p.chat.SetCell(row, 3, tview.NewTableCell(strings.TrimSpace(line.Body)))
 	}
@@ -428,7 +473,7 @@ func (p *gamePage) updateChatTable() {
 }
 
 func (p *gamePage) refreshGameState(app *App) error {
-	g, err := app.client.GameState(p.gameID)
+	g, err := ogs.GameState(app.client, p.gameID)
 	if err != nil {
 		app.error("Refresh game state %v", err)
 		return err
@@ -450,11 +495,23 @@ func (p *gamePage) refreshGameState(app *App) error {
 }
 
 func (p *gamePage) Leave(app *App) {
-	// Disconnect game, stop refresh
-	p.ticker.Stop()
+	if app.onTV(p.game.GameID) {
+		app.stopTV()
+	}
+	p.close(app)
+	app.switchToPage(p.returnPage)
+}
+
+// Disconnect game, stop refresh and remove the page without switching to
+// another page
+func (p *gamePage) close(app *App) {
+	select {
+	case <-p.done: // Closed already, the page is kept in app.pages
+	default:
+		close(p.done)
+	}
 	app.client.GameDisconnect(p.game.GameID)
 	app.removePage(fmt.Sprintf("%d", p.game.GameID))
-	app.switchToPage(p.returnPage)
 }
 
 func (p *gamePage) setupKeys(app *App) {
@@ -462,54 +519,92 @@ func (p *gamePage) setupKeys(app *App) {
 	p.board.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		myTurn := p.gameState.IsMyTurn(app.client.UserID)
 
-		if event.Key() == tcell.KeyLeft || event.Rune() == 'h' {
+		switch app.keys.action("game", event) {
+		case "left":
 			if myTurn && p.cursor.X > 0 {
 				p.cursor.X--
 			}
 			return nil
-		} else if event.Key() == tcell.KeyDown || event.Rune() == 'j' {
+		case "down":
 			if myTurn && p.cursor.Y < size-1 {
 				p.cursor.Y++
 			}
 			return nil
-		} else if event.Key() == tcell.KeyUp || event.Rune() == 'k' {
+		case "up":
 			if myTurn && p.cursor.Y > 0 {
 				p.cursor.Y--
 			}
 			return nil
-		} else if event.Key() == tcell.KeyRight || event.Rune() == 'l' {
+		case "right":
 			if myTurn && p.cursor.X < size-1 {
 				p.cursor.X++
 			}
 			return nil
-		} else if event.Key() == tcell.KeyEnter {
+		case "play":
 			if myTurn && p.cursor.X != -1 && p.cursor.Y != -1 && p.gameState.Board[p.cursor.Y][p.cursor.X] == 0 {
 				app.client.GameMove(p.game.GameID, p.cursor.X, p.cursor.Y)
 				return nil
 			}
-		} else if event.Rune() == 'P' {
+		case "pass":
 			if myTurn {
 				app.confirm("Pass?", func() {
 					app.client.PassTurn(p.game.GameID)
 				})
 				return nil
 			}
-		} else if event.Rune() == 'R' {
+		case "resign":
 			if p.game.IsMyGame(app.client.UserID) {
 				app.confirm("Resign?", func() {
 					app.client.GameResign(p.game.GameID)
 				})
 				return nil
 			}
-		} else if event.Rune() == 'A' {
+		case "accept":
 			if p.game.IsMyGame(app.client.UserID) && p.game.Phase == googs.StoneRemovalPhase {
 				app.confirm("Accept stone removal?", func() {
 					app.client.GameRemovedStonesAccept(p.game.GameID, p.gameState)
 				})
 				return nil
 			}
-		} else if event.Rune() == 't' {
-			p.boardTheme = nextBoardTheme(p.boardTheme)
+			if p.game.Phase == googs.FinishedPhase && p.rematch.offer != nil {
+				p.acceptRematch(app)
+				return nil
+			}
+		case "rematch":
+			if p.game.IsMyGame(app.client.UserID) && p.game.Phase == googs.FinishedPhase && p.rematch.sentID == 0 {
+				p.offerRematch(app)
+				return nil
+			}
+		case "decline":
+			if offer := p.rematch.offer; p.game.Phase == googs.FinishedPhase && offer != nil {
+				app.confirm("Decline rematch?", func() {
+					app.loading(
+						func() error {
+							if err := ogs.CancelChallenge(app.client, offer.ID); err != nil {
+								app.error("Decline rematch %d %v", offer.ID, err)
+								return err
+							}
+							return nil
+						},
+						func() {
+							p.rematch.offer = nil
+							p.updateStatusAndHint(app)
+						},
+					)
+				})
+				return nil
+			}
+		case "theme":
+			p.boardTheme = app.cycleBoardTheme(p.gameID)
+			app.notify("Board theme %s", p.boardTheme)
+			return nil
+		case "game_theme":
+			if app.toggleGameTheme(p.gameID) {
+				app.notify("Board theme %s kept for this game", p.boardTheme)
+			} else {
+				p.boardTheme = app.boardTheme(p.gameID)
+				app.notify("Board theme %s as other games", p.boardTheme)
+			}
 			return nil
 		}
 
@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+const historyPageSize = 20
+
+type historyPage struct {
+	grid   *tview.Grid
+	next   *tview.Button
+	home   *tview.Button
+	watch  *tview.Button
+	logout *tview.Button
+	games  *tview.Table
+	status *tview.TextView
+	hint   *tview.TextView
+
+	done    chan struct{} // Closed to stop background work
+	page    int           // 1 based
+	history *ogs.GameHistory
+}
+
+func newHistoryPage(app *App) Page {
+	p := &historyPage{
+		grid:   tview.NewGrid(),
+		next:   tview.NewButton("Next (0)"),
+		home:   tview.NewButton("Home"),
+		watch:  tview.NewButton("Watch"),
+		logout: tview.NewButton("Logout"),
+		games:  tview.NewTable(),
+		status: tview.NewTextView(),
+		hint:   tview.NewTextView(),
+		done:   make(chan struct{}),
+		page:   1,
+	}
+
+	go func(done <-chan struct{}) {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// Do not Refresh() here, finished games do not change
+				newLabel := fmt.Sprintf("Next (%d)", len(app.nextBoard))
+				if newLabel != p.next.GetLabel() {
+					app.redraw(func() {
+						p.next.SetLabel(newLabel)
+					})
+				}
+			}
+		}
+	}(p.done)
+
+	p.next.SetSelectedFunc(func() {
+		if g := app.nextGameEntry(); g != nil {
+			app.switchToNewGamePage(g.ID, "")
+		}
+	})
+	p.home.SetSelectedFunc(func() {
+		app.switchToPage("home")
+	})
+	p.watch.SetSelectedFunc(func() {
+		app.switchToPage("watch")
+	})
+	p.logout.SetSelectedFunc(logoutFunc(app))
+
+	navbar := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(app.navStatus, 0, 1, false). // left spacer
+		AddItem(p.next, 10, 0, false).
+		AddItem(nil, 1, 0, false). // gap
+		AddItem(p.home, 10, 0, false).
+		AddItem(nil, 1, 0, false). // gap
+		AddItem(p.watch, 10, 0, false).
+		AddItem(nil, 1, 0, false). // gap
+		AddItem(p.logout, 10, 0, false)
+
+	p.games.SetSelectable(true, false).
+		SetBorder(true).
+		SetTitleAlign(tview.AlignCenter)
+	p.status.SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetTextColor(Styles.TertiaryTextColor)
+	p.hint.SetDynamicColors(true).
+		SetTextColor(Styles.SecondaryTextColor).
+		SetTextAlign(tview.AlignCenter).
+		SetText(app.keyHints("history", "↓↑jk select", "CR open", "next_page/prev_page"))
+
+	// Center align the game table and bottom hint in a 4x1 grid
+	p.grid.SetRows(1, 0, 1, 1)
+	p.grid.SetColumns(0)
+	// Row 0: navbar
+	p.grid.AddItem(navbar, 0, 0, 1, 1, 1, 0, false)
+	// Row 1: games table
+	p.grid.AddItem(p.games, 1, 0, 1, 1, 10, 60, true)
+	// Row 2: status
+	p.grid.AddItem(p.status, 2, 0, 1, 1, 1, 0, false)
+	// Row 3: hint
+	p.grid.AddItem(p.hint, 3, 0, 1, 1, 1, 0, false)
+
+	p.setupKeys(app)
+	return p
+}
+
+func (p *historyPage) Root() tview.Primitive {
+	return p.grid
+}
+
+func (p *historyPage) Focusables() []tview.Primitive {
+	return []tview.Primitive{p.games, p.next, p.home, p.watch, p.logout}
+}
+
+func (p *historyPage) Refresh(app *App) error {
+	if !app.client.LoggedIn() {
+		return nil
+	}
+
+	h, err := p.fetch(app, p.page)
+	if err != nil {
+		return err
+	}
+	p.history = h
+	return nil
+}
+
+func (p *historyPage) fetch(app *App, page int) (*ogs.GameHistory, error) {
+	h, err := ogs.FinishedGames(app.client, app.client.UserID, page, historyPageSize)
+	if err != nil {
+		app.error("Refresh history page %v", err)
+	}
+	return h, err
+}
+
+func (p *historyPage) pageCount() int {
+	if p.history == nil || p.history.Count == 0 {
+		return 1
+	}
+	return (p.history.Count + historyPageSize - 1) / historyPageSize
+}
+
+func (p *historyPage) Render(app *App) {
+	if p.history == nil {
+		return
+	}
+
+	p.status.SetText(fmt.Sprintf("Page %d of %d, total %d finished games", p.page, p.pageCount(), p.history.Count))
+	p.games.Clear()
+	p.games.Select(-1, -1)
+	p.games.SetTitle(fmt.Sprintf(" Finished Games (%d) ", p.history.Count))
+
+	// Headers
+	headers := []string{"#", "Ended", "Game", "Result", "Opponent", "Size", "Ranked"}
+	for col, h := range headers {
+		p.games.SetCell(0, col, tview.NewTableCell(h).SetSelectable(false))
+	}
+
+	// Rows
+	offset := (p.page - 1) * historyPageSize
+	for i, g := range p.history.Results {
+		result := g.Result(app.client.UserID)
+		p.games.SetCell(i+1, 0, tview.NewTableCell(fmt.Sprintf("%d", offset+i+1)))
+		p.games.SetCell(i+1, 1, tview.NewTableCell(g.Ended.Local().Format("2006-01-02")))
+		p.games.SetCell(i+1, 2, tview.NewTableCell(trimString(g.Name, 30)))
+		p.games.SetCell(i+1, 3, tview.NewTableCell(trimString(result, 24)).
+			SetTextColor(util.Cond(strings.HasPrefix(result, "Won"), Colors.SuccessColor, Styles.PrimaryTextColor)))
+		p.games.SetCell(i+1, 4, tview.NewTableCell(g.Opponent(app.client.UserID).String()))
+		p.games.SetCell(i+1, 5, tview.NewTableCell(fmt.Sprintf("%dx%d", g.Width, g.Height)))
+		p.games.SetCell(i+1, 6, tview.NewTableCell(util.Cond(g.Ranked, "✔ ", "")))
+	}
+
+	p.games.SetSelectedFunc(func(row, _ int) {
+		if len(p.history.Results) < 1 {
+			return
+		}
+		selected := p.history.Results[row-1]
+		p.status.SetText(fmt.Sprintf("Opening game %d ...", selected.ID))
+		app.switchToNewGamePage(selected.ID, "history")
+	})
+}
+
+// Stop background work when the page is discarded
+func (p *historyPage) stop(app *App) {
+	close(p.done)
+}
+
+func (p *historyPage) Leave(app *App) {
+	app.tui.Stop()
+}
+
+func (p *historyPage) setupKeys(app *App) {
+	turnPage := func(delta int) {
+		page := p.page + delta
+		if page < 1 || page > p.pageCount() {
+			return
+		}
+		// Page state changes on the UI goroutine only, when fetched
+		var h *ogs.GameHistory
+		app.loading(
+			func() (err error) {
+				h, err = p.fetch(app, page)
+				return err
+			},
+			func() {
+				p.page, p.history = page, h
+				p.Render(app)
+			},
+		)
+	}
+
+	p.games.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch app.keys.action("history", event) {
+		case "refresh":
+			app.loading(
+				func() error { return p.Refresh(app) },
+				func() { p.Render(app) },
+			)
+			return nil
+		case "next_page":
+			turnPage(1)
+			return nil
+		case "prev_page":
+			turnPage(-1)
+			return nil
+		}
+		return event
+	})
+}
@@ -7,45 +7,85 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
 )
 
 type homePage struct {
-	grid   *tview.Grid
-	next   *tview.Button
-	watch  *tview.Button
-	logout *tview.Button
-	games  *tview.Table
-	status *tview.TextView
-	hint   *tview.TextView
+	grid       *tview.Grid
+	next       *tview.Button
+	watch      *tview.Button
+	history    *tview.Button
+	logout     *tview.Button
+	lists      *tview.Flex // Games and challenges
+	games      *tview.Table
+	challenges *tview.Table // Only shown when there are any
+	preview    *previewPane
+	status     *tview.TextView
+	hint       *tview.TextView
 
-	ticker   *time.Ticker
-	overview *googs.Overview
+	done       chan struct{} // Closed to stop background work
+	overview   *googs.Overview
+	shown      []*googs.GameOverview // Sorted and filtered
+	challenged []ogs.Challenge       // Sent and received
+	received   map[int64]bool        // IDs of received challenges seen, nil before first fetch
 }
 
 func newHomePage(app *App) Page {
 	p := &homePage{
-		grid:   tview.NewGrid(),
-		next:   tview.NewButton("Next (0)"),
-		watch:  tview.NewButton("Watch"),
-		logout: tview.NewButton("Logout"),
-		games:  tview.NewTable(),
-		status: tview.NewTextView(),
-		hint:   tview.NewTextView(),
-		ticker: time.NewTicker(time.Second),
-	}
-
-	go func() {
-		for range p.ticker.C {
-			newLabel := fmt.Sprintf("Next (%d)", len(app.nextBoard))
-			if newLabel != p.next.GetLabel() {
-				p.Refresh(app)
-				app.redraw(func() {
-					p.next.SetLabel(newLabel)
-					p.Render(app)
-				})
+		grid:       tview.NewGrid(),
+		next:       tview.NewButton("Next (0)"),
+		watch:      tview.NewButton("Watch"),
+		history:    tview.NewButton("History"),
+		logout:     tview.NewButton("Logout"),
+		lists:      tview.NewFlex(),
+		games:      tview.NewTable(),
+		challenges: tview.NewTable(),
+		preview:    newPreviewPane(app),
+		status:     tview.NewTextView(),
+		hint:       tview.NewTextView(),
+		done:       make(chan struct{}),
+	}
+
+	go func(done <-chan struct{}) {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		lastPoll := time.Now()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if time.Since(lastPoll) >= app.cfg.UI.Refresh.Challenges.Std() {
+					lastPoll = time.Now()
+					// Only poll while shown, the page is refreshed when
+					// switched back to anyway
+					app.tui.QueueUpdate(func() {
+						if front, _ := app.root.GetFrontPage(); front == "home" {
+							go p.pollChallenges(app)
+						}
+					})
+				}
+				newLabel := fmt.Sprintf("Next (%d)", len(app.nextBoard))
+				if newLabel != p.next.GetLabel() {
+					ov, err := ogs.Overview(app.client)
+					if err != nil {
+						app.warn("Refresh home page %v", err)
+					}
+					app.redraw(func() {
+						p.next.SetLabel(newLabel)
+						if err == nil {
+							p.overview = ov
+							p.Render(app)
+						}
+					})
+				} else if p.preview.tick() {
+					app.redraw(nil)
+				}
 			}
 		}
-	}()
+	}(p.done)
 
 	p.next.SetSelectedFunc(func() {
 		if g := app.nextGameEntry(); g != nil {
@@ -55,14 +95,19 @@ func newHomePage(app *App) Page {
 	p.watch.SetSelectedFunc(func() {
 		app.switchToPage("watch")
 	})
+	p.history.SetSelectedFunc(func() {
+		app.switchToPage("history")
+	})
 	p.logout.SetSelectedFunc(logoutFunc(app))
 
 	navbar := tview.NewFlex().SetDirection(tview.FlexColumn).
-		AddItem(nil, 0, 1, false). // left spacer
+		AddItem(app.navStatus, 0, 1, false). // left spacer
 		AddItem(p.next, 10, 0, false).
 		AddItem(nil, 1, 0, false). // gap
 		AddItem(p.watch, 10, 0, false).
 		AddItem(nil, 1, 0, false). // gap
+		AddItem(p.history, 10, 0, false).
+		AddItem(nil, 1, 0, false). // gap
 		AddItem(p.logout, 10, 0, false)
 
 	p.games.SetSelectable(true, false).
@@ -74,19 +119,31 @@ func newHomePage(app *App) Page {
 	p.hint.SetDynamicColors(true).
 		SetTextColor(Styles.SecondaryTextColor).
 		SetTextAlign(tview.AlignCenter).
-		SetText(keyHints([]string{"↓↑jk select", "CR connect"}))
-
-	// Center align the game table and bottom hint in a 4x1 grid
-	p.grid.SetRows(1, 0, 1, 1)
-	p.grid.SetColumns(0)
-	// Row 0: navbar
-	p.grid.AddItem(navbar, 0, 0, 1, 1, 1, 0, false)
-	// Row 1: games table
-	p.grid.AddItem(p.games, 1, 0, 1, 1, 10, 60, true)
-	// Row 2: status
-	p.grid.AddItem(p.status, 2, 0, 1, 1, 1, 0, false)
-	// Row 3: hint
-	p.grid.AddItem(p.hint, 3, 0, 1, 1, 1, 0, false)
+		SetText(app.keyHints("home", "↓↑jk select", "CR connect", "sort", "filter", "challenge", "automatch", "open_challenges"))
+	p.challenges.SetSelectable(true, false).
+		SetBorder(true).
+		SetTitleAlign(tview.AlignCenter)
+	p.lists.SetDirection(tview.FlexRow).
+		AddItem(p.games, 0, 1, true).
+		AddItem(p.challenges, 0, 0, false)
+
+	// Center align the game table and bottom hint (may wrap) in a 4x2 grid,
+	// the preview pane only shows up on a wide enough screen unless hidden
+	// by config
+	p.grid.SetRows(1, 0, 1, 2)
+	p.grid.SetColumns(0, 48)
+	// Row 0: navbar, span 2 columns
+	p.grid.AddItem(navbar, 0, 0, 1, 2, 1, 0, false)
+	// Row 1: games (span 2 columns), or games and preview
+	p.grid.AddItem(p.lists, 1, 0, 1, 2, 10, 60, true)
+	if !p.preview.hidden {
+		p.grid.AddItem(p.lists, 1, 0, 1, 1, 10, 120, true)
+		p.grid.AddItem(p.preview.flex, 1, 1, 1, 1, 10, 120, false)
+	}
+	// Row 2: status, span 2 columns
+	p.grid.AddItem(p.status, 2, 0, 1, 2, 1, 0, false)
+	// Row 3: hint, span 2 columns
+	p.grid.AddItem(p.hint, 3, 0, 1, 2, 1, 0, false)
 
 	p.setupKeys(app)
 	return p
@@ -97,7 +154,10 @@ func (p *homePage) Root() tview.Primitive {
 }
 
 func (p *homePage) Focusables() []tview.Primitive {
-	return []tview.Primitive{p.games, p.next, p.watch, p.logout}
+	if len(p.challenged) > 0 {
+		return []tview.Primitive{p.games, p.challenges, p.next, p.watch, p.history, p.logout}
+	}
+	return []tview.Primitive{p.games, p.next, p.watch, p.history, p.logout}
 }
 
 func (p *homePage) Refresh(app *App) error {
@@ -105,24 +165,92 @@ func (p *homePage) Refresh(app *App) error {
 		return nil
 	}
 
-	ov, err := app.client.Overview()
+	ov, err := ogs.Overview(app.client)
 	if err != nil {
 		app.error("Refresh home page %v", err)
 		return err
 	}
 	p.overview = ov
+
+	// Games are still shown without the challenges, polled again later
+	challenges, err := ogs.MyChallenges(app.client)
+	if err != nil {
+		app.warn("Refresh challenges %v", err)
+		return nil
+	}
+	app.redraw(func() {
+		p.setChallenges(app, challenges)
+		p.renderChallenges(app)
+	})
 	return nil
 }
 
+// Fetch challenges in background and apply on the UI goroutine
+func (p *homePage) pollChallenges(app *App) {
+	challenges, err := ogs.MyChallenges(app.client)
+	if err != nil {
+		app.warn("Poll challenges %v", err)
+		return
+	}
+	app.redraw(func() {
+		p.setChallenges(app, challenges)
+		p.renderChallenges(app)
+	})
+}
+
+// Update challenges and notify about new ones received, must be called from
+// the UI goroutine
+func (p *homePage) setChallenges(app *App, challenges []ogs.Challenge) {
+	received := make(map[int64]bool)
+	for _, c := range challenges {
+		if c.Challenger.ID == app.client.UserID {
+			continue
+		}
+		received[c.ID] = true
+		if p.received != nil && !p.received[c.ID] {
+			app.notify("Challenge from %s: %s", c.Challenger.Player().String(), c.Game.Name)
+		}
+	}
+	p.challenged, p.received = challenges, received
+}
+
 func (p *homePage) Render(app *App) {
 	if p.overview == nil {
 		return
 	}
 
-	p.status.SetText(fmt.Sprintf("You have %d active games", len(p.overview.ActiveGames)))
+	// Keep previewing the selected game across refreshes
+	var selectedID int64
+	if row, _ := p.games.GetSelection(); row >= 1 && row <= len(p.shown) {
+		selectedID = p.shown[row-1].GameID
+	}
+
+	prefs := app.prefs
+	p.shown = nil
+	for i := range p.overview.ActiveGames {
+		g := &p.overview.ActiveGames[i]
+		if matchGame(&prefs.HomeFilter, &g.Game, app.client.UserID) {
+			p.shown = append(p.shown, g)
+		}
+	}
+	sortGames(p.shown, prefs.HomeSort, prefs.HomeReverse, app.client.UserID)
+
+	status := fmt.Sprintf("You have %d active games", len(p.overview.ActiveGames))
+	if f := filterString(&prefs.HomeFilter); f != "" {
+		status += fmt.Sprintf(", %d shown (%s)", len(p.shown), f)
+	}
+	if prefs.HomeSort != "" || prefs.HomeReverse {
+		by := util.Cond(prefs.HomeSort == "", "server order", prefs.HomeSort)
+		status += fmt.Sprintf(", sorted by %s%s", by, util.Cond(prefs.HomeReverse, " reversed", ""))
+		if hint := app.keyHint("home", "reverse"); hint != "" {
+			status += " (" + hint + ")"
+		}
+	}
+	p.status.SetText(status)
+	p.games.SetSelectionChangedFunc(nil) // Set again below
 	p.games.Clear()
 	p.games.Select(-1, -1)
-	p.games.SetTitle(fmt.Sprintf(" Active Games (%d) ", len(p.overview.ActiveGames)))
+	p.games.SetTitle(fmt.Sprintf(" Active Games (%d) ", len(p.shown)))
 
 	// Headers
 	headers := []string{"#", "Move", "Game", "Flags", "Opponent", "Clock", "Size"}
@@ -131,15 +259,15 @@ func (p *homePage) Render(app *App) {
 	}
 
 	// Rows
-	for i, g := range p.overview.ActiveGames {
+	for i, g := range p.shown {
 		p.games.SetCell(i+1, 0, tview.NewTableCell(fmt.Sprintf("%d", i+1)))
 		p.games.SetCell(i+1, 1, tview.NewTableCell(fmt.Sprintf("%3d", len(g.Moves))))
 		p.games.SetCell(i+1, 2, tview.NewTableCell(trimString(g.GameName, 30)))
-		handicap := cond(g.Handicap > 0, "🤏", "")
-		private := cond(g.Private, "🔒", "")
+		handicap := util.Cond(g.Handicap > 0, "🤏", "")
+		private := util.Cond(g.Private, "🔒", "")
 		p.games.SetCell(i+1, 3, tview.NewTableCell(handicap+private))
 		p.games.SetCell(i+1, 4, tview.NewTableCell(g.Opponent(app.client.UserID).String()))
-		turn := cond(g.Clock.CurrentPlayerID == g.Players.Black.ID, googs.PlayerBlack, googs.PlayerWhite)
+		turn := util.Cond(g.Clock.CurrentPlayerID == g.Players.Black.ID, googs.PlayerBlack, googs.PlayerWhite)
 		p.games.SetCell(i+1, 5, tview.NewTableCell(g.Clock.ComputeClock(&g.TimeControl, turn).String()))
 		p.games.SetCell(i+1, 6, tview.NewTableCell(fmt.Sprintf("%dx%d ", g.BoardSize(), g.BoardSize())))
 
@@ -150,14 +278,152 @@ func (p *homePage) Render(app *App) {
 		}
 	}
 
+	p.games.SetSelectionChangedFunc(func(row, _ int) {
+		if row < 1 || row > len(p.shown) {
+			p.preview.show(app, nil)
+			return
+		}
+		p.preview.show(app, &p.shown[row-1].Game)
+	})
+	reselected := false
+	for i, g := range p.shown {
+		if g.GameID == selectedID {
+			p.games.Select(i+1, 0) // Shows the preview
+			reselected = true
+			break
+		}
+	}
+	if !reselected {
+		p.preview.show(app, nil)
+	}
+
 	p.games.SetSelectedFunc(func(row, _ int) {
-		if len(p.overview.ActiveGames) < 1 {
+		if len(p.shown) < 1 {
 			return
 		}
-		selected := p.overview.ActiveGames[row-1]
-		p.status.SetText("Connecting to " + selected.URL() + " ...")
+		selected := p.shown[row-1]
+		p.status.SetText("Connecting to " + ogs.GameURL(app.client, selected.GameID) + " ...")
 		app.switchToNewGamePage(selected.GameID, "")
 	})
+
+	p.renderChallenges(app)
+}
+
+func (p *homePage) renderChallenges(app *App) {
+	p.challenges.Clear()
+	p.challenges.Select(-1, -1)
+	title := fmt.Sprintf(" Challenges (%d)", len(p.challenged))
+	for _, action := range []string{"accept", "cancel"} {
+		if hint := app.keyHint("challenges", action); hint != "" {
+			title += ", " + hint
+		}
+	}
+	p.challenges.SetTitle(title + " ")
+	p.lists.ResizeItem(p.challenges, util.Cond(len(p.challenged) > 0, len(p.challenged)+3, 0), 0)
+	if len(p.challenged) == 0 && p.challenges.HasFocus() {
+		app.tui.SetFocus(p.games)
+	}
+
+	// Headers
+	headers := []string{"#", "Game", "From/To", "Time", "Size", "Ranked"}
+	for col, h := range headers {
+		p.challenges.SetCell(0, col, tview.NewTableCell(h).SetSelectable(false))
+	}
+
+	// Rows
+	for i, c := range p.challenged {
+		incoming := c.Challenger.ID != app.client.UserID
+		opponent := "→ (open)"
+		if incoming {
+			opponent = "← " + c.Challenger.Player().String()
+		} else if c.Challenged != nil {
+			opponent = "→ " + c.Challenged.Player().String()
+		}
+		p.challenges.SetCell(i+1, 0, tview.NewTableCell(fmt.Sprintf("%d", i+1)))
+		p.challenges.SetCell(i+1, 1, tview.NewTableCell(trimString(c.Game.Name, 30)))
+		p.challenges.SetCell(i+1, 2, tview.NewTableCell(opponent))
+		p.challenges.SetCell(i+1, 3, tview.NewTableCell(c.Game.TimeControl))
+		p.challenges.SetCell(i+1, 4, tview.NewTableCell(fmt.Sprintf("%dx%d", c.Game.Width, c.Game.Height)))
+		p.challenges.SetCell(i+1, 5, tview.NewTableCell(util.Cond(c.Game.Ranked, "✔ ", "")))
+
+		if incoming {
+			for col := range headers {
+				p.challenges.GetCell(i+1, col).SetTextColor(Styles.TertiaryTextColor)
+			}
+		}
+	}
+
+	p.challenges.SetSelectedFunc(func(_, _ int) {
+		if c := p.selectedChallenge(); c != nil {
+			if c.Challenger.ID != app.client.UserID {
+				p.acceptChallenge(app, c)
+			} else {
+				p.cancelChallenge(app, c)
+			}
+		}
+	})
+}
+
+func (p *homePage) selectedChallenge() *ogs.Challenge {
+	if row, _ := p.challenges.GetSelection(); row >= 1 && row <= len(p.challenged) {
+		return &p.challenged[row-1]
+	}
+	return nil
+}
+
+// Cancel a challenge sent or decline one received
+func (p *homePage) cancelChallenge(app *App, c *ogs.Challenge) {
+	id := c.ID
+	incoming := c.Challenger.ID != app.client.UserID
+	verb := util.Cond(incoming, "Decline", "Cancel")
+	app.confirm(fmt.Sprintf("%s challenge %q?", verb, c.Game.Name), func() {
+		app.loading(
+			func() error {
+				if err := ogs.CancelChallenge(app.client, id); err != nil {
+					app.error("%s challenge %d %v", verb, id, err)
+					return err
+				}
+				app.info("%s challenge %d done", verb, id)
+				return p.Refresh(app)
+			},
+			func() { p.Render(app) },
+		)
+	})
+}
+
+func (p *homePage) acceptChallenge(app *App, c *ogs.Challenge) {
+	id, gameID := c.ID, c.Game.ID
+	message := fmt.Sprintf("Accept challenge %q from %s?", c.Game.Name, c.Challenger.Player().String())
+	app.confirm(message, func() {
+		app.loading(
+			func() error {
+				newGameID, err := ogs.AcceptChallenge(app.client, id)
+				if err != nil {
+					app.error("Accept challenge %d %v", id, err)
+					return err
+				}
+				gameID = util.Cond(newGameID != 0, newGameID, gameID)
+				app.info("Accepted challenge %d, game %d", id, gameID)
+				return nil
+			},
+			func() {
+				if gameID != 0 {
+					app.switchToNewGamePage(gameID, "home")
+				}
+			},
+		)
+	})
+}
+
+// Disconnect the previewed game, which is shown again upon next Render
+func (p *homePage) Hide(app *App) {
+	p.preview.show(app, nil)
+}
+
+// Stop background work when the page is discarded
+func (p *homePage) stop(app *App) {
+	close(p.done)
+	p.preview.show(app, nil)
 }
 
 func (p *homePage) Leave(app *App) {
@@ -165,14 +431,59 @@ func (p *homePage) Leave(app *App) {
 }
 
 func (p *homePage) setupKeys(app *App) {
+	p.challenges.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch app.keys.action("challenges", event) {
+		case "accept":
+			if c := p.selectedChallenge(); c != nil && c.Challenger.ID != app.client.UserID {
+				p.acceptChallenge(app, c)
+			}
+			return nil
+		case "cancel":
+			if c := p.selectedChallenge(); c != nil {
+				p.cancelChallenge(app, c)
+			}
+			return nil
+		case "challenge":
+			app.switchToPage("challenge")
+			return nil
+		}
+		return event
+	})
+
 	p.games.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Rune() {
-		case 'r':
+		switch app.keys.action("home", event) {
+		case "refresh":
 			app.loading(
 				func() error { return p.Refresh(app) },
 				func() { p.Render(app) },
 			)
 			return nil
+		case "sort":
+			app.prefs.HomeSort = nextSortKey(homeSortKeys, app.prefs.HomeSort)
+			app.savePrefs()
+			p.Render(app)
+			return nil
+		case "reverse":
+			app.prefs.HomeReverse = !app.prefs.HomeReverse
+			app.savePrefs()
+			p.Render(app)
+			return nil
+		case "filter":
+			fields := []string{filterFieldMyTurn, filterFieldSize, filterFieldRanked, filterFieldSpeed}
+			app.editFilter(&app.prefs.HomeFilter, fields, func() {
+				app.savePrefs()
+				p.Render(app)
+			})
+			return nil
+		case "challenge":
+			app.switchToPage("challenge")
+			return nil
+		case "automatch":
+			app.startAutomatch()
+			return nil
+		case "open_challenges":
+			app.switchToPage("seek")
+			return nil
 		}
 		return event
 	})
@@ -0,0 +1,284 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+// Key bindings are grouped by scope, a scope is active along with the
+// common scope, e.g. the "game" scope when the board of a game page is
+// focused. Keys are either a single character like "P", or a tcell key name
+// like "Enter", "Left" or "Ctrl-N".
+type binding struct {
+	action string
+	keys   []string
+	desc   string // Hint description
+}
+
+const commonScope = "common"
+
+// Default bindings. Table navigation (arrows, jk, Enter) and Tab/Esc are
+// handled by widgets and are not remappable.
+var defaultBindings = map[string][]binding{
+	commonScope: {
+		{"logs", []string{"D"}, "Debug logs"},
+		{"home", []string{"H"}, "Home"},
+		{"next", []string{"N"}, "Next"},
+		{"watch", []string{"W"}, "Watch"},
+		{"scheme", []string{"C"}, "Colors"},
+		{"accounts", []string{"U"}, "User accounts"},
+		{"quit", []string{"q"}, "quit"},
+	},
+	"game": {
+		{"left", []string{"Left", "h"}, "move cursor"},
+		{"down", []string{"Down", "j"}, ""},
+		{"up", []string{"Up", "k"}, ""},
+		{"right", []string{"Right", "l"}, ""},
+		{"play", []string{"Enter"}, "play"},
+		{"pass", []string{"P"}, "Pass"},
+		{"resign", []string{"R"}, "Resign"},
+		{"accept", []string{"A"}, "Accept"},
+		{"rematch", []string{"m"}, "match again"},
+		{"decline", []string{"x"}, "decline"},
+		{"theme", []string{"t"}, "theme"},
+		{"game_theme", []string{"T"}, "Theme for this game"},
+	},
+	"home": {
+		{"refresh", []string{"r"}, "refresh"},
+		{"sort", []string{"s"}, "sort"},
+		{"reverse", []string{"S"}, "reverse sort"},
+		{"filter", []string{"f"}, "filter"},
+		{"challenge", []string{"c"}, "challenge"},
+		{"automatch", []string{"a"}, "automatch"},
+		{"open_challenges", []string{"o"}, "open challenges"},
+	},
+	"challenges": {
+		{"accept", []string{"a"}, "accept"},
+		{"cancel", []string{"x"}, "cancel or decline"},
+		{"challenge", []string{"c"}, "challenge"},
+	},
+	"watch": {
+		{"refresh", []string{"r"}, "refresh"},
+		{"auto_refresh", []string{"a"}, "auto"},
+		{"tv", []string{"T"}, "TV"},
+		{"list", []string{"c"}, "list"},
+		{"follow", []string{"+"}, "follow"},
+		{"unfollow", []string{"-"}, "unfollow"},
+		{"filter", []string{"f"}, "filter"},
+		{"next_page", []string{"n"}, "page"},
+		{"prev_page", []string{"p"}, "previous page"},
+	},
+	"history": {
+		{"refresh", []string{"r"}, "refresh"},
+		{"next_page", []string{"n"}, "page"},
+		{"prev_page", []string{"p"}, "previous page"},
+	},
+}
+
+// Keys handled before any binding
+var reservedKeys = []string{"Tab", "Backtab", "Esc"}
+
+// Scopes active on pages with tables, and the keys tables navigate with, which
+// bindings there would shadow
+var (
+	tableScopes = []string{commonScope, "home", "challenges", "watch", "history"}
+	tableKeys   = []string{"Up", "Down", "Left", "Right", "j", "k", "h", "l", "g", "G",
+		"Home", "End", "PgUp", "PgDn", "Ctrl-F", "Ctrl-B", "Enter"}
+)
+
+// Shorter labels of named keys in hints
+var keyLabels = map[string]string{
+	"Left":  "←",
+	"Down":  "↓",
+	"Up":    "↑",
+	"Right": "→",
+	"Enter": "CR",
+}
+
+type keymap struct {
+	bindings map[string][]binding
+	lookup   map[string]map[string]string // Scope => key => action
+}
+
+// Build the keymap from defaults with overrides of scope => action => keys,
+// conflicting keys are reported as errors.
+func newKeymap(overrides map[string]map[string][]string) (*keymap, error) {
+	km := &keymap{
+		bindings: make(map[string][]binding),
+		lookup:   make(map[string]map[string]string),
+	}
+	for scope, bindings := range defaultBindings {
+		km.bindings[scope] = append([]binding(nil), bindings...)
+	}
+
+	for scope, actions := range overrides {
+		bindings, ok := km.bindings[scope]
+		if !ok {
+			return nil, fmt.Errorf("unknown key scope %q, valid scopes: %s", scope, strings.Join(keyScopes(), ", "))
+		}
+		for action, keys := range actions {
+			i := indexOfBinding(bindings, action)
+			if i < 0 {
+				return nil, fmt.Errorf("unknown action %q in key scope %q", action, scope)
+			}
+			for _, key := range keys {
+				if err := validateKey(key); err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", scope, action, err)
+				}
+			}
+			bindings[i].keys = keys
+		}
+	}
+
+	for _, scope := range keyScopes() {
+		km.lookup[scope] = make(map[string]string)
+		for _, b := range km.bindings[scope] {
+			for _, key := range b.keys {
+				if oneOf(scope, tableScopes...) && oneOf(key, tableKeys...) {
+					return nil, fmt.Errorf("key %q of %s.%s is for navigating tables", key, scope, b.action)
+				}
+				if other, ok := km.lookup[scope][key]; ok {
+					return nil, fmt.Errorf("key %q bound to both %s.%s and %s.%s", key, scope, other, scope, b.action)
+				}
+				if other, ok := km.lookup[commonScope][key]; ok && scope != commonScope {
+					return nil, fmt.Errorf("key %q bound to both %s.%s and %s.%s", key, commonScope, other, scope, b.action)
+				}
+				km.lookup[scope][key] = b.action
+			}
+		}
+	}
+	return km, nil
+}
+
+// Sorted scopes, common first
+func keyScopes() []string {
+	scopes := []string{commonScope}
+	for scope := range defaultBindings {
+		if scope != commonScope {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes[1:])
+	return scopes
+}
+
+func oneOf(s string, choices ...string) bool {
+	for _, c := range choices {
+		if s == c {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOfBinding(bindings []binding, action string) int {
+	for i, b := range bindings {
+		if b.action == action {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateKey(key string) error {
+	if utf8.RuneCountInString(key) == 1 {
+		return nil
+	}
+	for _, reserved := range reservedKeys {
+		if key == reserved {
+			return fmt.Errorf("key %q is reserved", key)
+		}
+	}
+	for _, name := range tcell.KeyNames {
+		if key == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid key %q, must be a single character or a key name like \"Enter\" or \"Ctrl-N\"", key)
+}
+
+// Name of the key event, in the same form as in bindings
+func keyName(event *tcell.EventKey) string {
+	if event.Key() == tcell.KeyRune {
+		return string(event.Rune())
+	}
+	return tcell.KeyNames[event.Key()]
+}
+
+// Action bound to the key event in the scope, empty if none
+func (km *keymap) action(scope string, event *tcell.EventKey) string {
+	return km.lookup[scope][keyName(event)]
+}
+
+// Render a hint of the actions joined by "/", e.g. "next_page/prev_page" =>
+// "n/p page", empty if unbound. Keys are interleaved if all actions have multiple keys, e.g.
+// "←↓↑→hjkl". The description is from the first action.
+func (km *keymap) hint(scope, actions string) (string, error) {
+	var bindings []*binding
+	interleave := strings.Contains(actions, "/")
+	for _, action := range strings.Split(actions, "/") {
+		b := km.binding(scope, action)
+		if b == nil {
+			return "", fmt.Errorf("no binding %s.%s", scope, action)
+		}
+		bindings = append(bindings, b)
+		interleave = interleave && len(b.keys) > 1
+	}
+	desc := bindings[0].desc
+
+	var label string
+	if interleave {
+		rounds := 0
+		for _, b := range bindings {
+			rounds = util.Cond(len(b.keys) > rounds, len(b.keys), rounds)
+		}
+		var sb strings.Builder
+		for i := 0; i < rounds; i++ {
+			for _, b := range bindings {
+				if i < len(b.keys) {
+					sb.WriteString(keyLabel(b.keys[i]))
+				}
+			}
+		}
+		label = sb.String()
+	} else {
+		var labels []string
+		for _, b := range bindings {
+			var sb strings.Builder
+			for _, key := range b.keys {
+				sb.WriteString(keyLabel(key))
+			}
+			labels = append(labels, sb.String())
+		}
+		label = strings.Join(labels, "/")
+	}
+	if strings.Trim(label, "/") == "" {
+		return "", nil // Unbound
+	}
+
+	// "Pass" bound to "P" => "[::r]P[::-]ass"
+	if len(bindings) == 1 && utf8.RuneCountInString(label) == 1 && strings.HasPrefix(desc, label) {
+		return fmt.Sprintf("[::r]%s[::-]%s", label, desc[len(label):]), nil
+	}
+	// "play" bound to "Enter" => "[::r]CR[::-] play"
+	return fmt.Sprintf("[::r]%s[::-] %s", label, desc), nil
+}
+
+func keyLabel(key string) string {
+	return util.Cond(keyLabels[key] != "", keyLabels[key], key)
+}
+
+func (km *keymap) binding(scope, action string) *binding {
+	for _, s := range []string{scope, commonScope} {
+		if i := indexOfBinding(km.bindings[s], action); i >= 0 {
+			return &km.bindings[s][i]
+		}
+	}
+	return nil
+}
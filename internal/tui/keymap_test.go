@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestNewKeymap(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]map[string][]string
+		wantErr   string // Substring, empty for valid
+	}{
+		{"defaults", nil, ""},
+		{"remap", map[string]map[string][]string{"game": {"pass": {"p"}, "left": {"Left", "a"}}}, ""},
+		{"unbind", map[string]map[string][]string{"watch": {"tv": {}}}, ""},
+		{"named key", map[string]map[string][]string{"common": {"quit": {"Ctrl-Q"}}}, ""},
+		{"unknown scope", map[string]map[string][]string{"board": {"pass": {"p"}}}, "unknown key scope"},
+		{"unknown action", map[string]map[string][]string{"game": {"undo": {"u"}}}, "unknown action"},
+		{"invalid key", map[string]map[string][]string{"game": {"pass": {"Pass"}}}, "invalid key"},
+		{"reserved key", map[string]map[string][]string{"game": {"pass": {"Tab"}}}, "reserved"},
+		{"conflict in scope", map[string]map[string][]string{"game": {"pass": {"R"}}}, "bound to both game.pass and game.resign"},
+		{"conflict with common", map[string]map[string][]string{"home": {"sort": {"q"}}}, "bound to both common.quit and home.sort"},
+		{"table key", map[string]map[string][]string{"home": {"sort": {"j"}}}, "navigating tables"},
+		{"table key in common", map[string]map[string][]string{"common": {"logs": {"Down"}}}, "navigating tables"},
+		{"table key in game", map[string]map[string][]string{"game": {"pass": {"g"}}}, ""},
+	}
+	for _, tc := range tests {
+		_, err := newKeymap(tc.overrides)
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		case tc.wantErr != "" && err == nil:
+			t.Errorf("%s: no error, want %q", tc.name, tc.wantErr)
+		case tc.wantErr != "" && !strings.Contains(err.Error(), tc.wantErr):
+			t.Errorf("%s: error %v, want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestKeymapAction(t *testing.T) {
+	km, err := newKeymap(map[string]map[string][]string{
+		"game": {"pass": {"p"}, "left": {"Left", "a"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		scope string
+		event *tcell.EventKey
+		want  string
+	}{
+		{"game", tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone), "pass"},
+		{"game", tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone), ""},
+		{"game", tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone), "left"},
+		{"game", tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), "left"},
+		{"game", tcell.NewEventKey(tcell.KeyRune, 'h', tcell.ModNone), ""},
+		{"game", tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), "play"},
+		{"common", tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone), "quit"},
+		{"home", tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone), ""},
+		{"watch", tcell.NewEventKey(tcell.KeyRune, 'T', tcell.ModNone), "tv"},
+	}
+	for _, tc := range tests {
+		if got := km.action(tc.scope, tc.event); got != tc.want {
+			t.Errorf("action(%q, %q) = %q, want %q", tc.scope, keyName(tc.event), got, tc.want)
+		}
+	}
+}
+
+func TestKeymapHint(t *testing.T) {
+	km, err := newKeymap(map[string]map[string][]string{
+		"watch": {"tv": {}},
+		"game":  {"pass": {"p"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		scope, actions string
+		want           string
+		wantErr        bool
+	}{
+		{"game", "resign", "[::r]R[::-]esign", false},
+		{"game", "pass", "[::r]p[::-] Pass", false},
+		{"game", "play", "[::r]CR[::-] play", false},
+		{"game", "left/down/up/right", "[::r]←↓↑→hjkl[::-] move cursor", false},
+		{"watch", "next_page/prev_page", "[::r]n/p[::-] page", false},
+		{"watch", "tv", "", false},
+		{"watch", "quit", "[::r]q[::-]uit", false}, // Common
+		{"watch", "undo", "", true},
+	}
+	for _, tc := range tests {
+		got, err := km.hint(tc.scope, tc.actions)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("hint(%q, %q) error %v, want error %v", tc.scope, tc.actions, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("hint(%q, %q) = %q, want %q", tc.scope, tc.actions, got, tc.want)
+		}
+	}
+}
@@ -1,18 +1,27 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"os/exec"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/rivo/tview"
+
+	"github.com/ymattw/tenuki/internal/ogs"
 )
 
+// How long to wait for approval of login in a browser
+const browserLoginTimeout = 5 * time.Minute
+
 func newLoginPage(app *App, callback func()) tview.Primitive {
 	status := tview.NewTextView().
 		SetDynamicColors(true).
 		SetTextAlign(tview.AlignCenter).
 		SetTextColor(Styles.MoreContrastBackgroundColor).
-		SetText("See your OGS \"Application\" or register one at\nhttps://online-go.com/oauth2/applications/")
+		SetText("See your OGS \"Application\" or register one at\n" + app.cfg.Server.REST() + "/oauth2/applications/")
 
 	cField := tview.NewInputField().
 		SetLabel("Client ID").
@@ -26,7 +35,8 @@ func newLoginPage(app *App, callback func()) tview.Primitive {
 		SetPlaceholderTextColor(Styles.MoreContrastBackgroundColor)
 	uField := tview.NewInputField().
 		SetLabel("Username").
-		SetFieldWidth(42)
+		SetFieldWidth(42).
+		SetText(app.client.Username) // Known when logging in again
 	pField := tview.NewInputField().
 		SetLabel("Password").
 		SetFieldWidth(42).
@@ -37,16 +47,32 @@ func newLoginPage(app *App, callback func()) tview.Primitive {
 		AddFormItem(sField).
 		AddFormItem(uField).
 		AddFormItem(pField)
+	// Take client fields from the form, false if they look wrong
+	setClient := func() bool {
+		app.client.ClientID = cField.GetText()
+		app.client.ClientSecret = sField.GetText()
+		if strings.HasPrefix(app.client.ClientSecret, "pbkdf2_sha") {
+			status.SetText("[red]Client Secret looks to be hashed, try a different combination[-]")
+			app.tui.SetFocus(form.GetFormItemByLabel("Client Secret"))
+			return false
+		}
+		return true
+	}
+	var auth *ogs.Authorization // Pending login in browser
+	cancelAuth := func() {
+		if auth != nil {
+			auth.Close()
+			auth = nil
+		}
+	}
+
 	form.SetButtonsAlign(tview.AlignCenter).
 		AddButton("Submit", func() {
-			app.client.ClientID = cField.GetText()
-			app.client.ClientSecret = sField.GetText()
-			if strings.HasPrefix(app.client.ClientSecret, "pbkdf2_sha") {
-				status.SetText("[red]Client Secret looks to be hashed, try a different combination[-]")
-				app.tui.SetFocus(form.GetFormItemByLabel("Client Secret"))
+			cancelAuth()
+			if !setClient() {
 				return
 			}
-			if err := app.client.Login(uField.GetText(), pField.GetText()); err != nil {
+			if err := ogs.Login(app.client, uField.GetText(), pField.GetText()); err != nil {
 				status.SetText(fmt.Sprintf("[red]%v[-]", err))
 				app.tui.SetFocus(form.GetFormItemByLabel("Password"))
 			} else {
@@ -54,16 +80,52 @@ func newLoginPage(app *App, callback func()) tview.Primitive {
 				status.SetText("[green]Success, switching to home page ...")
 			}
 		}).
+		AddButton("Browser", func() {
+			cancelAuth()
+			if !setClient() {
+				return
+			}
+			a, err := ogs.StartAuthorization(app.client, app.cfg.Login.RedirectURI)
+			if err != nil {
+				status.SetText(fmt.Sprintf("[red]%v[-]", err))
+				return
+			}
+			auth = a
+			app.info("Login in browser at %s", a.URL)
+			openBrowser(app, a.URL)
+			status.SetText("Approve in the browser, or open\n" + a.URL)
+			go func() {
+				client, err := a.Wait(browserLoginTimeout)
+				app.redraw(func() {
+					switch {
+					case errors.Is(err, ogs.ErrAuthorizationCanceled):
+					case err != nil:
+						auth = nil
+						status.SetText(fmt.Sprintf("[red]%v[-]", err))
+					default:
+						auth = nil
+						app.client = client
+						callback()
+						status.SetText("[green]Success, switching to home page ...")
+					}
+				})
+			}()
+		}).
 		AddButton("Quit", func() {
+			cancelAuth()
 			app.tui.Stop()
 		}).
 		SetTitle(" Login to OGS ").
 		SetBorder(true)
 
+	if app.client.ClientID != "" && app.client.Username != "" {
+		form.SetFocus(form.GetFormItemIndex("Password"))
+	}
+
 	status.SetDynamicColors(true).
 		SetTextAlign(tview.AlignCenter).
 		SetTextColor(Styles.MoreContrastBackgroundColor).
-		SetText("See your OGS OAuth2 Application or register one at\nhttps://online-go.com/oauth2/applications/")
+		SetText("See your OGS OAuth2 Application or register one at\n" + app.cfg.Server.REST() + "/oauth2/applications/")
 
 	// Center align the form and bottom status in a 3x3 grid
 	grid := tview.NewGrid().
@@ -73,3 +135,21 @@ func newLoginPage(app *App, callback func()) tview.Primitive {
 		AddItem(status, 2, 1, 1, 1, 0, 0, false)
 	return grid
 }
+
+// Best effort, the URL is also shown on the login page
+func openBrowser(app *App, url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		app.debug("Open browser %v", err)
+		return
+	}
+	go cmd.Wait()
+}
@@ -3,8 +3,6 @@ package tui
 import (
 	"fmt"
 	"os"
-
-	"github.com/ymattw/tenuki/internal/config"
 )
 
 func logoutFunc(app *App) func() {
@@ -13,7 +11,7 @@ func logoutFunc(app *App) func() {
 			[]string{"Logout", "Quit instead", "Cancel"},
 			map[string]func(){
 				"Logout": func() {
-					os.Remove(config.SecretPath(app.client.Username))
+					os.Remove(app.cfg.Server.SecretPath(app.client.Username))
 					app.tui.Stop()
 				},
 				"Quit instead": func() {
@@ -0,0 +1,197 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+const previewChatLines = 5
+
+// Cached preview of a game, keyed by game ID
+type gamePreview struct {
+	state     *googs.GameState // Replayed from game moves
+	moves     int              // Number of moves the state was built from
+	chats     []*googs.GameChatLine
+	connected bool // Game connected by the preview
+}
+
+// Side pane of the home page showing the selected game at a glance
+type previewPane struct {
+	flex   *tview.Flex
+	board  *tview.Box
+	clocks *tview.TextView
+	chat   *tview.TextView
+
+	hidden bool // Disabled by config
+
+	game  *googs.Game // Game being previewed, nil for none
+	cache map[int64]*gamePreview
+	lock  sync.Mutex
+}
+
+func newPreviewPane(app *App) *previewPane {
+	p := &previewPane{
+		hidden: app.cfg.UI.Layout.HidePreview,
+		flex:   tview.NewFlex(),
+		board:  tview.NewBox(),
+		clocks: tview.NewTextView(),
+		chat:   tview.NewTextView(),
+		cache:  make(map[int64]*gamePreview),
+	}
+
+	p.board.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		if p.game == nil {
+			return x, y, width, height
+		}
+		state := p.cache[p.game.GameID].state
+		// Center the board horizontally
+		x += (width - state.BoardSize()*2 - 6) / 2
+		hidden := &googs.OriginCoordinate{X: -1, Y: -1}
+		return drawBoard(screen, x, y, state, app.boardTheme(p.game.GameID), hidden, googs.PlayerUnknown)
+	})
+	p.clocks.SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	p.chat.SetDynamicColors(true).
+		SetWrap(false).
+		SetTextColor(Styles.SecondaryTextColor)
+
+	p.flex.SetDirection(tview.FlexRow).
+		AddItem(p.board, 0, 0, false).
+		AddItem(p.clocks, 3, 0, false).
+		AddItem(p.chat, 0, 1, false)
+	p.flex.SetBorder(true).
+		SetTitle(" Preview ").
+		SetTitleAlign(tview.AlignCenter)
+	return p
+}
+
+// Show the given game, or clear the pane when g is nil. The game is connected
+// for chat lines while shown, which are kept in cache afterwards.
+func (p *previewPane) show(app *App, g *googs.Game) {
+	if p.hidden {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.game != nil && (g == nil || g.GameID != p.game.GameID) {
+		p.disconnect(app, p.game.GameID)
+	}
+	p.game = g
+	if g == nil {
+		p.flex.ResizeItem(p.board, 0, 0)
+		p.clocks.SetText("")
+		p.chat.SetText("")
+		return
+	}
+
+	entry := p.cache[g.GameID]
+	if entry == nil {
+		entry = &gamePreview{}
+		p.cache[g.GameID] = entry
+	}
+	if entry.state == nil || entry.moves != len(g.Moves) {
+		entry.state = replayGameState(g)
+		entry.moves = len(g.Moves)
+	}
+	p.connect(app, g.GameID, entry)
+
+	p.flex.ResizeItem(p.board, g.BoardSize()+2, 0)
+	p.updateClocks()
+	p.updateChat(entry)
+}
+
+// Must be called with lock held
+func (p *previewPane) connect(app *App, gameID int64, entry *gamePreview) {
+	// Either already connected, or an open game page owns the connection
+	if entry.connected || app.root.HasPage(fmt.Sprintf("%d", gameID)) {
+		return
+	}
+	// Registered on every connect since a game page replaces the handler,
+	// the server sends the chat history again upon connect
+	app.client.OnGameChat(gameID, func(chat *googs.GameChat) {
+		p.lock.Lock()
+		entry.chats = insertSortedChats(entry.chats, &chat.Line)
+		p.lock.Unlock()
+		app.redraw(func() {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			if p.game != nil && p.game.GameID == gameID {
+				p.updateChat(entry)
+			}
+		})
+	})
+	if err := app.client.GameConnect(gameID); err != nil {
+		app.error("Preview game %d %v", gameID, err)
+		return
+	}
+	entry.connected = true
+}
+
+// Must be called with lock held
+func (p *previewPane) disconnect(app *App, gameID int64) {
+	entry := p.cache[gameID]
+	if entry == nil || !entry.connected {
+		return
+	}
+	if !app.root.HasPage(fmt.Sprintf("%d", gameID)) {
+		app.client.GameDisconnect(gameID)
+	}
+	entry.connected = false
+}
+
+// Update clock displays, return true if anything changed. Must be called with
+// lock held.
+func (p *previewPane) updateClocks() bool {
+	if p.game == nil {
+		return false
+	}
+	var lines []string
+	for _, c := range []googs.PlayerColor{googs.PlayerBlack, googs.PlayerWhite} {
+		stone := util.Cond(c == googs.PlayerBlack, BlackStone, WhiteStone)
+		player := util.Cond(c == googs.PlayerBlack, p.game.BlackPlayer(), p.game.WhitePlayer())
+		clock := p.game.Clock.ComputeClock(&p.game.TimeControl, c)
+		style := util.Cond(clock.SuddenDeath, "[red]", "")
+		turn := util.Cond(p.game.Clock.CurrentPlayerID == player.ID, " [::l]•[::-]", "")
+		lines = append(lines, fmt.Sprintf("%c %s %s%s[-]%s", stone, player, style, clock, turn))
+	}
+	text := strings.Join(lines, "\n")
+	if text == p.clocks.GetText(false) {
+		return false
+	}
+	p.clocks.SetText(text)
+	return true
+}
+
+// Must be called with lock held
+func (p *previewPane) updateChat(entry *gamePreview) {
+	lines := entry.chats
+	if len(lines) > previewChatLines {
+		lines = lines[len(lines)-previewChatLines:]
+	}
+	var texts []string
+	for _, line := range lines {
+		texts = append(texts, fmt.Sprintf("[%s]%s[-]: %s",
+			Styles.TertiaryTextColor.CSS(), tview.Escape(line.Username), tview.Escape(strings.TrimSpace(line.Body))))
+	}
+	if len(texts) == 0 {
+		texts = append(texts, "[::d]No chat yet[::-]")
+	}
+	p.chat.SetText(strings.Join(texts, "\n"))
+}
+
+// Called every second from the page ticker, return true if redraw is needed
+func (p *previewPane) tick() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.updateClocks()
+}
@@ -0,0 +1,231 @@
+package tui
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+// Rematch state of a finished game page
+type rematch struct {
+	sentID     int64          // Challenge sent, 0 if none
+	sentGameID int64          // Game to be started by the challenge sent
+	offer      *ogs.Challenge // Challenge received from the opponent
+	lastPoll   time.Time
+	polling    int32 // Accessed atomically, 1 while a poll is in flight
+}
+
+// Build a challenge with the same settings of the game, with my color kept or
+// swapped.
+func rematchRequest(g *googs.Game, myUserID int64, swap bool) *ogs.ChallengeRequest {
+	myColor := util.Cond(g.Players.Black.ID == myUserID, "black", "white")
+	if swap {
+		myColor = util.Cond(myColor == "black", "white", "black")
+	}
+	komi := g.Komi
+	return &ogs.ChallengeRequest{
+		MinRanking:      -1000,
+		MaxRanking:      1000,
+		ChallengerColor: myColor,
+		Game: ogs.ChallengeGame{
+			Name:                  g.GameName,
+			Rules:                 g.Rules,
+			Ranked:                g.Ranked,
+			Private:               g.Private,
+			Width:                 g.Width,
+			Height:                g.Height,
+			Handicap:              g.Handicap,
+			KomiAuto:              "custom",
+			Komi:                  &komi,
+			PauseOnWeekends:       g.TimeControl.PauseOnWeekends,
+			TimeControl:           string(g.TimeControl.System),
+			TimeControlParameters: gameTimeControlParameters(&g.TimeControl),
+		},
+	}
+}
+
+// OGS time control parameters of a loaded game
+func gameTimeControlParameters(tc *googs.TimeControl) map[string]any {
+	params := map[string]any{
+		"system":            tc.System,
+		"time_control":      tc.System,
+		"speed":             tc.Speed,
+		"pause_on_weekends": tc.PauseOnWeekends,
+	}
+	switch tc.System {
+	case googs.ClockAbsolute:
+		params["total_time"] = tc.TotalTime
+	case googs.ClockByoyomi:
+		params["main_time"] = tc.MainTime
+		params["period_time"] = tc.PeriodTime
+		params["periods"] = tc.Periods
+	case googs.ClockCanadian:
+		params["main_time"] = tc.MainTime
+		params["period_time"] = tc.PeriodTime
+		params["stones_per_period"] = tc.StonesPerPeriod
+	case googs.ClockFischer:
+		params["initial_time"] = tc.InitialTime
+		params["time_increment"] = tc.TimeIncrement
+		params["max_time"] = tc.MaxTime
+	case googs.ClockSimple:
+		params["per_move"] = tc.PerMove
+	}
+	return params
+}
+
+// Offer a rematch to the opponent
+func (p *gamePage) offerRematch(app *App) {
+	opponent := p.game.Opponent(app.client.UserID)
+	send := func(swap bool) func() {
+		return func() {
+			req := rematchRequest(p.game, app.client.UserID, swap)
+			var id, gameID int64
+			app.loading(
+				func() error {
+					var err error
+					if id, gameID, err = ogs.CreateChallenge(app.client, opponent.ID, req); err != nil {
+						app.error("Offer rematch %v", err)
+						return err
+					}
+					app.info("Offered rematch to %s, challenge %d of game %d", opponent.Username, id, gameID)
+					return nil
+				},
+				func() {
+					p.rematch.sentID, p.rematch.sentGameID = id, gameID
+					p.updateStatusAndHint(app)
+				},
+			)
+		}
+	}
+	message := fmt.Sprintf("Offer %s a rematch with the same settings?", opponent.String())
+	app.popUp(message, []string{"Same colors", "Swap colors", "Cancel"}, map[string]func(){
+		"Same colors": send(false),
+		"Swap colors": send(true),
+	})
+}
+
+func (p *gamePage) acceptRematch(app *App) {
+	offer := p.rematch.offer
+	id, gameID := offer.ID, offer.Game.ID
+	app.confirm(fmt.Sprintf("Accept rematch from %s?", offer.Challenger.Player().String()), func() {
+		app.loading(
+			func() error {
+				newGameID, err := ogs.AcceptChallenge(app.client, id)
+				if err != nil {
+					app.error("Accept rematch %d %v", id, err)
+					return err
+				}
+				gameID = util.Cond(newGameID != 0, newGameID, gameID)
+				app.info("Accepted rematch %d, game %d", id, gameID)
+				return nil
+			},
+			func() { p.switchToRematch(app, gameID) },
+		)
+	})
+}
+
+// Poll challenges for a rematch offered by the opponent, or the one sent
+// being answered. Called from the page ticker, challenges are fetched in
+// another goroutine and applied in app.redraw().
+func (p *gamePage) pollRematch(app *App) {
+	if !p.rematchable(app) && p.rematch.sentID == 0 || time.Since(p.rematch.lastPoll) < app.cfg.UI.Refresh.Rematch.Std() {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&p.rematch.polling, 0, 1) {
+		return
+	}
+	p.rematch.lastPoll = time.Now()
+
+	go func() {
+		defer atomic.StoreInt32(&p.rematch.polling, 0)
+		challenges, err := ogs.MyChallenges(app.client)
+		if err != nil {
+			app.warn("Poll rematch %v", err)
+			return
+		}
+		app.redraw(func() { p.applyRematch(app, challenges) })
+	}()
+}
+
+// Only my games finished in this session, older games opened e.g. from the
+// history page are not followed by rematches
+func (p *gamePage) rematchable(app *App) bool {
+	return p.game.Phase == googs.FinishedPhase && p.game.IsMyGame(app.client.UserID) &&
+		p.game.Clock.LastMove.After(app.sessionStart)
+}
+
+// Whether the challenge is a rematch offer of the game from the opponent, with
+// the same settings and created after the game ended.
+func isRematchOffer(c *ogs.Challenge, g *googs.Game, opponentID int64) bool {
+	return c.Challenger.ID == opponentID &&
+		c.Game.Width == g.Width && c.Game.Height == g.Height &&
+		c.Game.Rules == g.Rules && c.Game.Handicap == g.Handicap &&
+		c.Game.TimeControl == string(g.TimeControl.System) &&
+		c.Created.After(g.Clock.LastMove.Time)
+}
+
+func (p *gamePage) applyRematch(app *App, challenges []ogs.Challenge) {
+	opponent := p.game.Opponent(app.client.UserID)
+	var offer *ogs.Challenge
+	sentPending := false
+	rematchable := p.rematchable(app)
+	for i := range challenges {
+		if rematchable && isRematchOffer(&challenges[i], p.game, opponent.ID) {
+			offer = &challenges[i]
+		}
+		if challenges[i].ID == p.rematch.sentID {
+			sentPending = true
+		}
+	}
+	if offer != nil && p.rematch.offer == nil {
+		app.notify("%s offers a rematch", offer.Challenger.Player().String())
+	}
+	p.rematch.offer = offer
+
+	// The challenge sent is gone, either accepted or declined
+	if p.rematch.sentID != 0 && !sentPending {
+		gameID := p.rematch.sentGameID
+		p.rematch.sentID, p.rematch.sentGameID = 0, 0
+		go func() {
+			g, err := ogs.Game(app.client, gameID)
+			app.redraw(func() {
+				if err == nil && g.Phase == googs.PlayPhase {
+					app.notify("Rematch accepted, game %d", gameID)
+					p.switchToRematch(app, gameID)
+				} else {
+					app.notify("Rematch declined by %s", opponent.String())
+				}
+			})
+		}()
+	}
+	p.updateStatusAndHint(app)
+}
+
+func (p *gamePage) switchToRematch(app *App, gameID int64) {
+	if gameID == 0 {
+		return
+	}
+	returnPage := p.returnPage
+	p.close(app)
+	app.switchToNewGamePage(gameID, returnPage)
+}
+
+// Rematch status and hints on a finished game page
+func (p *gamePage) rematchStatusAndHints(app *App) (string, []string) {
+	if !p.game.IsMyGame(app.client.UserID) {
+		return "", nil
+	}
+	if p.rematch.offer != nil {
+		return fmt.Sprintf(", %s offers a rematch", p.rematch.offer.Challenger.Player().String()),
+			[]string{"accept", "decline"}
+	}
+	if p.rematch.sentID != 0 {
+		return ", waiting for rematch answer", nil
+	}
+	return "", []string{"rematch"}
+}
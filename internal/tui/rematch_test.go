@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ymattw/googs"
+	"github.com/ymattw/tenuki/internal/ogs"
+)
+
+func TestIsRematchOffer(t *testing.T) {
+	ended := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	g := &googs.Game{Width: 19, Height: 19, Rules: "japanese", Handicap: 0}
+	g.TimeControl.System = googs.ClockByoyomi
+	g.Clock.LastMove.Time = ended
+
+	offer := func(modify func(c *ogs.Challenge)) *ogs.Challenge {
+		c := &ogs.Challenge{Created: ended.Add(time.Minute)}
+		c.Challenger.ID = 2
+		c.Game.Width, c.Game.Height, c.Game.Rules, c.Game.TimeControl = 19, 19, "japanese", "byoyomi"
+		modify(c)
+		return c
+	}
+	tests := []struct {
+		name   string
+		modify func(c *ogs.Challenge)
+		want   bool
+	}{
+		{"same settings", func(c *ogs.Challenge) {}, true},
+		{"other challenger", func(c *ogs.Challenge) { c.Challenger.ID = 3 }, false},
+		{"other size", func(c *ogs.Challenge) { c.Game.Width, c.Game.Height = 9, 9 }, false},
+		{"other rules", func(c *ogs.Challenge) { c.Game.Rules = "chinese" }, false},
+		{"other handicap", func(c *ogs.Challenge) { c.Game.Handicap = 2 }, false},
+		{"other time control", func(c *ogs.Challenge) { c.Game.TimeControl = "fischer" }, false},
+		{"created before the end", func(c *ogs.Challenge) { c.Created = ended.Add(-time.Minute) }, false},
+	}
+	for _, tc := range tests {
+		if got := isRematchOffer(offer(tc.modify), g, 2); got != tc.want {
+			t.Errorf("%s: isRematchOffer() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/ymattw/tenuki/internal/ogs"
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+// Open challenges from the seek graph, updated in real time
+type seekPage struct {
+	grid   *tview.Grid
+	next   *tview.Button
+	home   *tview.Button
+	watch  *tview.Button
+	logout *tview.Button
+	games  *tview.Table
+	status *tview.TextView
+	hint   *tview.TextView
+
+	done      chan struct{} // Closed to stop background work
+	connected bool
+	entries   map[int64]ogs.SeekEntry // Key is challenge ID
+	shown     []ogs.SeekEntry         // Sorted
+	lock      sync.Mutex
+}
+
+func newSeekPage(app *App) Page {
+	p := &seekPage{
+		grid:    tview.NewGrid(),
+		next:    tview.NewButton("Next (0)"),
+		home:    tview.NewButton("Home"),
+		watch:   tview.NewButton("Watch"),
+		logout:  tview.NewButton("Logout"),
+		games:   tview.NewTable(),
+		status:  tview.NewTextView(),
+		hint:    tview.NewTextView(),
+		done:    make(chan struct{}),
+		entries: make(map[int64]ogs.SeekEntry),
+	}
+
+	go func(done <-chan struct{}) {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// No Refresh() here, updates are pushed
+				newLabel := fmt.Sprintf("Next (%d)", len(app.nextBoard))
+				if newLabel != p.next.GetLabel() {
+					app.redraw(func() {
+						p.next.SetLabel(newLabel)
+					})
+				}
+			}
+		}
+	}(p.done)
+
+	p.next.SetSelectedFunc(func() {
+		if g := app.nextGameEntry(); g != nil {
+			app.switchToNewGamePage(g.ID, "")
+		}
+	})
+	p.home.SetSelectedFunc(func() {
+		p.Leave(app)
+	})
+	p.watch.SetSelectedFunc(func() {
+		app.switchToPage("watch")
+	})
+	p.logout.SetSelectedFunc(logoutFunc(app))
+
+	navbar := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(app.navStatus, 0, 1, false). // left spacer
+		AddItem(p.next, 10, 0, false).
+		AddItem(nil, 1, 0, false). // gap
+		AddItem(p.home, 10, 0, false).
+		AddItem(nil, 1, 0, false). // gap
+		AddItem(p.watch, 10, 0, false).
+		AddItem(nil, 1, 0, false). // gap
+		AddItem(p.logout, 10, 0, false)
+
+	p.games.SetSelectable(true, false).
+		SetBorder(true).
+		SetTitleAlign(tview.AlignCenter)
+	p.status.SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetTextColor(Styles.TertiaryTextColor)
+	p.hint.SetDynamicColors(true).
+		SetTextColor(Styles.SecondaryTextColor).
+		SetTextAlign(tview.AlignCenter).
+		SetText(app.keyHints("seek", "↓↑jk select", "CR accept"))
+
+	// Center align the game table and bottom hint in a 4x1 grid
+	p.grid.SetRows(1, 0, 1, 1)
+	p.grid.SetColumns(0)
+	// Row 0: navbar
+	p.grid.AddItem(navbar, 0, 0, 1, 1, 1, 0, false)
+	// Row 1: challenges table
+	p.grid.AddItem(p.games, 1, 0, 1, 1, 10, 60, true)
+	// Row 2: status
+	p.grid.AddItem(p.status, 2, 0, 1, 1, 1, 0, false)
+	// Row 3: hint
+	p.grid.AddItem(p.hint, 3, 0, 1, 1, 1, 0, false)
+
+	return p
+}
+
+func (p *seekPage) Root() tview.Primitive {
+	return p.grid
+}
+
+func (p *seekPage) Focusables() []tview.Primitive {
+	return []tview.Primitive{p.games, p.next, p.home, p.watch, p.logout}
+}
+
+// Subscribe to the seek graph, entries arrive asynchronously
+func (p *seekPage) Refresh(app *App) error {
+	if !app.client.LoggedIn() || p.connected {
+		return nil
+	}
+
+	s, err := app.realtime()
+	if err != nil {
+		app.error("Connect realtime %v", err)
+		return err
+	}
+	p.lock.Lock()
+	p.entries = make(map[int64]ogs.SeekEntry)
+	p.lock.Unlock()
+	if err := s.SeekGraphConnect(func(entries []ogs.SeekEntry) { p.update(app, entries) }); err != nil {
+		app.error("Connect seek graph %v", err)
+		return err
+	}
+	p.connected = true
+	return nil
+}
+
+// Called from the socket
+func (p *seekPage) update(app *App, entries []ogs.SeekEntry) {
+	p.lock.Lock()
+	for _, e := range entries {
+		if e.Delete != 0 {
+			delete(p.entries, e.ChallengeID)
+		} else if e.ChallengeID != 0 {
+			p.entries[e.ChallengeID] = e
+		}
+	}
+	p.lock.Unlock()
+
+	if front, _ := app.root.GetFrontPage(); front == "seek" {
+		app.redraw(func() { p.Render(app) })
+	}
+}
+
+// Unsubscribe when switched away from by any means, subscribed again upon
+// next Refresh
+func (p *seekPage) Hide(app *App) {
+	p.disconnect(app)
+}
+
+func (p *seekPage) disconnect(app *App) {
+	if !p.connected {
+		return
+	}
+	p.connected = false
+	if s, err := app.realtime(); err == nil {
+		s.SeekGraphDisconnect()
+	}
+}
+
+func (p *seekPage) Render(app *App) {
+	// Keep selected challenge across updates
+	var selectedID int64
+	if row, _ := p.games.GetSelection(); row >= 1 && row <= len(p.shown) {
+		selectedID = p.shown[row-1].ChallengeID
+	}
+
+	p.lock.Lock()
+	p.shown = nil
+	for _, e := range p.entries {
+		p.shown = append(p.shown, e)
+	}
+	p.lock.Unlock()
+	// Fastest first, then newest
+	sort.Slice(p.shown, func(i, j int) bool {
+		a, b := p.shown[i], p.shown[j]
+		if a.TimePerMove != b.TimePerMove {
+			return a.TimePerMove < b.TimePerMove
+		}
+		return a.ChallengeID > b.ChallengeID
+	})
+
+	p.status.SetText(fmt.Sprintf("%d open challenges, updated in real time", len(p.shown)))
+	p.games.Clear()
+	p.games.Select(-1, -1)
+	p.games.SetTitle(fmt.Sprintf(" Open Challenges (%d) ", len(p.shown)))
+
+	// Headers
+	headers := []string{"Player", "Rank", "Size", "Time", "Handicap", "Komi", "Ranked", "Rules", "Name"}
+	for col, h := range headers {
+		p.games.SetCell(0, col, tview.NewTableCell(h).SetSelectable(false))
+	}
+
+	// Rows
+	for i, e := range p.shown {
+		player := e.Player()
+		komi := "auto"
+		if e.Komi != nil {
+			komi = fmt.Sprintf("%.1f", *e.Komi)
+		}
+		p.games.SetCell(i+1, 0, tview.NewTableCell(e.Username))
+		p.games.SetCell(i+1, 1, tview.NewTableCell(player.Ranking()))
+		p.games.SetCell(i+1, 2, tview.NewTableCell(fmt.Sprintf("%dx%d", e.Width, e.Height)))
+		p.games.SetCell(i+1, 3, tview.NewTableCell(fmt.Sprintf("%s %s", speedOf(int64(e.TimePerMove)), e.TimeControl)))
+		p.games.SetCell(i+1, 4, tview.NewTableCell(util.Cond(e.Handicap < 0, "auto", fmt.Sprintf("%d", e.Handicap))))
+		p.games.SetCell(i+1, 5, tview.NewTableCell(komi))
+		p.games.SetCell(i+1, 6, tview.NewTableCell(util.Cond(e.Ranked, "✔ ", "")))
+		p.games.SetCell(i+1, 7, tview.NewTableCell(e.Rules))
+		p.games.SetCell(i+1, 8, tview.NewTableCell(trimString(e.Name, 30)))
+
+		// Is my challenge
+		if e.UserID == app.client.UserID {
+			for col := range headers {
+				p.games.GetCell(i+1, col).SetTextColor(Styles.TertiaryTextColor)
+			}
+		}
+		if e.ChallengeID == selectedID {
+			p.games.Select(i+1, 0)
+		}
+	}
+
+	p.games.SetSelectedFunc(func(row, _ int) {
+		if row < 1 || row > len(p.shown) {
+			return
+		}
+		selected := p.shown[row-1]
+		if selected.UserID == app.client.UserID {
+			p.status.SetText("[red]Unable to accept own challenge[-]")
+			return
+		}
+		p.accept(app, &selected)
+	})
+}
+
+func (p *seekPage) accept(app *App, e *ogs.SeekEntry) {
+	id, gameID := e.ChallengeID, e.GameID
+	player := e.Player()
+	message := fmt.Sprintf("Accept %dx%d challenge from %s?", e.Width, e.Height, player.String())
+	app.confirm(message, func() {
+		app.loading(
+			func() error {
+				newGameID, err := ogs.AcceptOpenChallenge(app.client, id)
+				if err != nil {
+					app.error("Accept open challenge %d %v", id, err)
+					return err
+				}
+				gameID = util.Cond(newGameID != 0, newGameID, gameID)
+				app.info("Accepted open challenge %d, game %d", id, gameID)
+				return nil
+			},
+			func() {
+				if gameID != 0 {
+					app.switchToNewGamePage(gameID, "home")
+				}
+			},
+		)
+	})
+}
+
+// Stop background work when the page is discarded
+func (p *seekPage) stop(app *App) {
+	close(p.done)
+	p.disconnect(app)
+}
+
+func (p *seekPage) Leave(app *App) {
+	app.switchToPage("home")
+}
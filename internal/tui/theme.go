@@ -1,15 +1,20 @@
 package tui
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+
+	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/util"
 )
 
 var (
 	Styles       = &tview.Styles
-	StyleDefault = tcell.StyleDefault.Background(solarizedBase03).Foreground(solarizedBase0)
+	StyleDefault tcell.Style // Set by applyUIScheme()
+	Colors       uiScheme    // Roles not in tview.Styles, set by applyUIScheme()
 )
 
 var (
@@ -31,50 +36,303 @@ var (
 	solarizedGreen   = tcell.NewHexColor(0x859900)
 )
 
+// UI color scheme, applied to tview.Styles
+type uiScheme struct {
+	Background                 tcell.Color
+	ContrastBackgroundColor    tcell.Color // Background color for contrasting elements, eg. inactive buttons
+	MoreContrastBackground     tcell.Color // Background color for even more contrasting elements
+	BorderColor                tcell.Color
+	GraphicsColor              tcell.Color
+	PrimaryTextColor           tcell.Color
+	SecondaryTextColor         tcell.Color
+	TertiaryTextColor          tcell.Color // Tertiary text (e.g. subtitles, notes)
+	InverseTextColor           tcell.Color
+	ContrastSecondaryTextColor tcell.Color // Secondary text on ContrastBackgroundColor-colored backgrounds
+	HighlightColor             tcell.Color // Text of changed items, eg. updated games
+	NotificationColor          tcell.Color // Background of notifications, text of alerts
+	NotificationTextColor      tcell.Color // Text on NotificationColor-colored backgrounds
+	SuccessColor               tcell.Color // Text of good news, eg. finished games and wins
+}
+
+const defaultUIScheme = "solarized-dark"
+
+var uiSchemes = map[string]uiScheme{
+	"solarized-dark": {
+		Background:                 solarizedBase03,
+		ContrastBackgroundColor:    solarizedBase02,
+		MoreContrastBackground:     solarizedBase01,
+		BorderColor:                solarizedBase00,
+		GraphicsColor:              solarizedBase01,
+		PrimaryTextColor:           solarizedBase0,
+		SecondaryTextColor:         solarizedBase1,
+		TertiaryTextColor:          solarizedBase00,
+		InverseTextColor:           solarizedBase3,
+		ContrastSecondaryTextColor: solarizedCyan,
+		HighlightColor:             solarizedYellow,
+		NotificationColor:          solarizedYellow,
+		NotificationTextColor:      solarizedBase03,
+		SuccessColor:               solarizedGreen,
+	},
+	"solarized-light": {
+		Background:                 solarizedBase3,
+		ContrastBackgroundColor:    solarizedBase2,
+		MoreContrastBackground:     solarizedBase1,
+		BorderColor:                solarizedBase1,
+		GraphicsColor:              solarizedBase1,
+		PrimaryTextColor:           solarizedBase00,
+		SecondaryTextColor:         solarizedBase01,
+		TertiaryTextColor:          solarizedBase1,
+		InverseTextColor:           solarizedBase03,
+		ContrastSecondaryTextColor: solarizedBlue,
+		HighlightColor:             solarizedYellow,
+		NotificationColor:          solarizedYellow,
+		NotificationTextColor:      solarizedBase03,
+		SuccessColor:               solarizedGreen,
+	},
+	"high-contrast": {
+		Background:                 tcell.NewHexColor(0x000000),
+		ContrastBackgroundColor:    tcell.NewHexColor(0x303030),
+		MoreContrastBackground:     tcell.NewHexColor(0x505050),
+		BorderColor:                tcell.NewHexColor(0xffffff),
+		GraphicsColor:              tcell.NewHexColor(0xffffff),
+		PrimaryTextColor:           tcell.NewHexColor(0xffffff),
+		SecondaryTextColor:         tcell.NewHexColor(0xffff00),
+		TertiaryTextColor:          tcell.NewHexColor(0x00ffff),
+		InverseTextColor:           tcell.NewHexColor(0x000000),
+		ContrastSecondaryTextColor: tcell.NewHexColor(0xffff00),
+		HighlightColor:             tcell.NewHexColor(0xffff00),
+		NotificationColor:          tcell.NewHexColor(0xffff00),
+		NotificationTextColor:      tcell.NewHexColor(0x000000),
+		SuccessColor:               tcell.NewHexColor(0x00ff00),
+	},
+	// Okabe-Ito palette, distinguishable with common color vision
+	// deficiencies
+	"colorblind": {
+		Background:                 tcell.NewHexColor(0x1c1c1c),
+		ContrastBackgroundColor:    tcell.NewHexColor(0x333333),
+		MoreContrastBackground:     tcell.NewHexColor(0x4d4d4d),
+		BorderColor:                tcell.NewHexColor(0x999999),
+		GraphicsColor:              tcell.NewHexColor(0x999999),
+		PrimaryTextColor:           tcell.NewHexColor(0xe0e0e0),
+		SecondaryTextColor:         tcell.NewHexColor(0xf0e442), // yellow
+		TertiaryTextColor:          tcell.NewHexColor(0x56b4e9), // sky blue
+		InverseTextColor:           tcell.NewHexColor(0x000000),
+		ContrastSecondaryTextColor: tcell.NewHexColor(0xe69f00), // orange
+		HighlightColor:             tcell.NewHexColor(0xf0e442),
+		NotificationColor:          tcell.NewHexColor(0xe69f00),
+		NotificationTextColor:      tcell.NewHexColor(0x000000),
+		SuccessColor:               tcell.NewHexColor(0x009e73), // bluish green
+	},
+	// Terminal's own background and foreground, with palette colors
+	"terminal": {
+		Background:                 tcell.ColorDefault,
+		ContrastBackgroundColor:    tcell.ColorGray,
+		MoreContrastBackground:     tcell.ColorSilver,
+		BorderColor:                tcell.ColorGray,
+		GraphicsColor:              tcell.ColorGray,
+		PrimaryTextColor:           tcell.ColorDefault,
+		SecondaryTextColor:         tcell.ColorDefault,
+		TertiaryTextColor:          tcell.ColorTeal,
+		InverseTextColor:           tcell.ColorBlack,
+		ContrastSecondaryTextColor: tcell.ColorWhite,
+		HighlightColor:             tcell.ColorOlive,
+		NotificationColor:          tcell.ColorOlive,
+		NotificationTextColor:      tcell.ColorBlack,
+		SuccessColor:               tcell.ColorGreen,
+	},
+}
+
 func init() {
-	Styles.PrimitiveBackgroundColor = solarizedBase03
-	Styles.ContrastBackgroundColor = solarizedBase02     // Background color for contrasting elements, eg. inactive buttons
-	Styles.MoreContrastBackgroundColor = solarizedBase01 // Background color for even more contrasting elements
-	Styles.BorderColor = solarizedBase00
-	Styles.TitleColor = solarizedBase00
-	Styles.GraphicsColor = solarizedBase01
-	Styles.PrimaryTextColor = solarizedBase0
-	Styles.SecondaryTextColor = solarizedBase1
-	Styles.TertiaryTextColor = solarizedBase00 // Tertiary text (e.g. subtitles, notes)
-	Styles.InverseTextColor = solarizedBase3
-	Styles.ContrastSecondaryTextColor = solarizedCyan // Secondary text on ContrastBackgroundColor-colored backgrounds
+	applyUIScheme(defaultUIScheme)
+}
+
+// Name of the scheme in effect
+var appliedUIScheme string
+
+// Set global styles, widgets created afterwards pick them up
+func applyUIScheme(name string) {
+	appliedUIScheme = name
+	s := uiSchemes[name]
+	Styles.PrimitiveBackgroundColor = s.Background
+	Styles.ContrastBackgroundColor = s.ContrastBackgroundColor
+	Styles.MoreContrastBackgroundColor = s.MoreContrastBackground
+	Styles.BorderColor = s.BorderColor
+	Styles.TitleColor = s.BorderColor
+	Styles.GraphicsColor = s.GraphicsColor
+	Styles.PrimaryTextColor = s.PrimaryTextColor
+	Styles.SecondaryTextColor = s.SecondaryTextColor
+	Styles.TertiaryTextColor = s.TertiaryTextColor
+	Styles.InverseTextColor = s.InverseTextColor
+	Styles.ContrastSecondaryTextColor = s.ContrastSecondaryTextColor
+	StyleDefault = tcell.StyleDefault.Background(s.Background).Foreground(s.PrimaryTextColor)
+	Colors = s
+}
+
+func uiSchemeNames() []string {
+	var keys []string
+	for k := range uiSchemes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 type BoardTheme struct {
-	GridFG      tcell.Color
-	BoardBG     tcell.Color
-	LastBlackBG tcell.Color
-	LastWhiteBG tcell.Color
+	GridFG           tcell.Color
+	BoardBG          tcell.Color
+	LastBlackBG      tcell.Color
+	LastWhiteBG      tcell.Color
+	CursorBlack      tcell.Color
+	CursorWhite      tcell.Color
+	TerritoryBlackBG tcell.Color
+	TerritoryWhiteBG tcell.Color
+	DeadBG           tcell.Color
 }
 
+// Built-in themes, user themes from config are added by addBoardThemes()
 var boardThemes = map[string]BoardTheme{
 	"night": {
-		GridFG:      tcell.NewHexColor(0x1f1f1f), // gray
-		BoardBG:     tcell.NewHexColor(0x666666), // dark gray
-		LastBlackBG: solarizedOrange,
-		LastWhiteBG: solarizedRed,
+		GridFG:           tcell.NewHexColor(0x1f1f1f), // gray
+		BoardBG:          tcell.NewHexColor(0x666666), // dark gray
+		LastBlackBG:      solarizedOrange,
+		LastWhiteBG:      solarizedRed,
+		CursorBlack:      tcell.ColorBlack,
+		CursorWhite:      tcell.ColorWhite,
+		TerritoryBlackBG: tcell.NewHexColor(0x3d3d3d),
+		TerritoryWhiteBG: tcell.NewHexColor(0x999999),
+		DeadBG:           solarizedViolet,
 	},
 	"oak": {
-		GridFG:      tcell.NewHexColor(0x1f1f1f), // gray
-		BoardBG:     tcell.NewHexColor(0x7c4c38), // reddish-brown
-		LastBlackBG: solarizedOrange,
-		LastWhiteBG: solarizedRed,
+		GridFG:           tcell.NewHexColor(0x1f1f1f), // gray
+		BoardBG:          tcell.NewHexColor(0x7c4c38), // reddish-brown
+		LastBlackBG:      solarizedOrange,
+		LastWhiteBG:      solarizedRed,
+		CursorBlack:      tcell.ColorBlack,
+		CursorWhite:      tcell.ColorWhite,
+		TerritoryBlackBG: tcell.NewHexColor(0x4a2c20),
+		TerritoryWhiteBG: tcell.NewHexColor(0xb08068),
+		DeadBG:           solarizedViolet,
 	},
 }
 
-// Return the theme next to the current (sorted by key)
-func nextBoardTheme(current string) string {
+// Add user defined themes, which may also redefine built-in ones
+func addBoardThemes(themes map[string]config.BoardTheme) error {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	added := make(map[string]BoardTheme)
+	for _, name := range names {
+		t := themes[name]
+		baseName := util.Cond(t.Base != "", t.Base, "night")
+		base, ok := boardThemes[baseName]
+		if !ok {
+			return fmt.Errorf("ui.board_themes.%s: unknown base theme %q", name, baseName)
+		}
+		for _, c := range []struct {
+			field string
+			value string
+			color *tcell.Color
+		}{
+			{"grid", t.Grid, &base.GridFG},
+			{"board", t.Board, &base.BoardBG},
+			{"last_black", t.LastBlack, &base.LastBlackBG},
+			{"last_white", t.LastWhite, &base.LastWhiteBG},
+			{"cursor_black", t.CursorBlack, &base.CursorBlack},
+			{"cursor_white", t.CursorWhite, &base.CursorWhite},
+			{"territory_black", t.TerritoryBlack, &base.TerritoryBlackBG},
+			{"territory_white", t.TerritoryWhite, &base.TerritoryWhiteBG},
+			{"dead", t.Dead, &base.DeadBG},
+		} {
+			if c.value == "" {
+				continue
+			}
+			color := tcell.GetColor(c.value)
+			if color == tcell.ColorDefault {
+				return fmt.Errorf("ui.board_themes.%s.%s: invalid color %q", name, c.field, c.value)
+			}
+			*c.color = color
+		}
+		added[name] = base
+	}
+	for name, t := range added {
+		boardThemes[name] = t
+	}
+	return nil
+}
+
+// Board theme of the game: chosen for the game, or chosen in the app, or the
+// default from config. Themes gone from config are ignored.
+func (app *App) boardTheme(gameID int64) string {
+	for _, name := range []string{app.prefs.GameThemes[gameID], app.prefs.BoardTheme} {
+		if _, ok := boardThemes[name]; ok {
+			return name
+		}
+	}
+	return app.cfg.UI.BoardTheme
+}
+
+// Cycle board theme of the game, remembered for the game if it has its own
+// theme, otherwise as the default.
+func (app *App) cycleBoardTheme(gameID int64) string {
+	theme := nextBoardTheme(app.boardTheme(gameID))
+	if _, ok := app.prefs.GameThemes[gameID]; ok {
+		app.prefs.GameThemes[gameID] = theme
+	} else {
+		app.prefs.BoardTheme = theme
+	}
+	app.savePrefs()
+	return theme
+}
+
+// Toggle whether the game has its own board theme
+func (app *App) toggleGameTheme(gameID int64) bool {
+	if _, ok := app.prefs.GameThemes[gameID]; ok {
+		delete(app.prefs.GameThemes, gameID)
+		app.savePrefs()
+		return false
+	}
+	if app.prefs.GameThemes == nil {
+		app.prefs.GameThemes = make(map[int64]string)
+	}
+	app.prefs.GameThemes[gameID] = app.boardTheme(gameID)
+	pruneGameThemes(app.prefs.GameThemes, maxGameThemes)
+	app.savePrefs()
+	return true
+}
+
+// Games with their own board themes to remember at most
+const maxGameThemes = 100
+
+// Forget themes of the oldest games over the limit, game IDs are increasing
+func pruneGameThemes(themes map[int64]string, limit int) {
+	if len(themes) <= limit {
+		return
+	}
+	ids := make([]int64, 0, len(themes))
+	for id := range themes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids[:len(ids)-limit] {
+		delete(themes, id)
+	}
+}
+
+func boardThemeNames() []string {
 	var keys []string
 	for k := range boardThemes {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
+	return keys
+}
 
+// Return the theme next to the current (sorted by key)
+func nextBoardTheme(current string) string {
+	keys := boardThemeNames()
 	for i, k := range keys {
 		if k == current {
 			return keys[(i+1)%len(keys)]
@@ -82,3 +340,103 @@ func nextBoardTheme(current string) string {
 	}
 	return keys[0]
 }
+
+// Color scheme in use: chosen in the app, or the default from config
+func (app *App) uiScheme() string {
+	if _, ok := uiSchemes[app.prefs.UIScheme]; ok {
+		return app.prefs.UIScheme
+	}
+	return app.cfg.UI.Scheme
+}
+
+// Switch to the next color scheme and remember it
+func (app *App) cycleUIScheme() string {
+	names := uiSchemeNames()
+	name := names[(indexOf(names, app.uiScheme())+1)%len(names)]
+	app.setUIScheme(name)
+	return name
+}
+
+func (app *App) setUIScheme(name string) {
+	app.prefs.UIScheme = name
+	app.savePrefs()
+	applyUIScheme(name)
+	app.restyleShared()
+	app.rebuildPages()
+}
+
+// Restyle widgets living across pages
+func (app *App) restyleShared() {
+	app.root.SetBackgroundColor(Styles.PrimitiveBackgroundColor)
+	app.navStatus.SetTextColor(Styles.TertiaryTextColor).
+		SetBackgroundColor(Styles.PrimitiveBackgroundColor)
+	app.logger.SetTextStyle(StyleDefault.Background(Styles.ContrastBackgroundColor)).
+		SetBackgroundColor(Styles.ContrastBackgroundColor).
+		SetBorderColor(Styles.BorderColor).
+		SetTitleColor(Styles.TitleColor)
+}
+
+// Widgets take styles when created, so pages are recreated to pick up a new
+// scheme. Page states like selections and filters are reset.
+func (app *App) rebuildPages() {
+	front, _ := app.root.GetFrontPage()
+	app.root.RemovePage("login")
+	app.addLoginPage()
+	if !app.client.LoggedIn() || app.pages["home"] == nil {
+		return
+	}
+
+	for _, name := range staticPages {
+		if old, ok := app.pages[name].(interface{ stop(*App) }); ok {
+			old.stop(app)
+		}
+		app.root.RemovePage(name)
+	}
+	app.addStaticPages()
+	for name, page := range app.pages {
+		if p, ok := page.(*gamePage); ok && app.root.HasPage(name) {
+			p.close(app)
+			app.addPage(name, newGamePage(app, p.gameID, p.returnPage))
+		}
+	}
+	if app.root.HasPage(front) && app.pages[front] != nil {
+		app.switchToPage(front)
+	} else {
+		app.switchToPage("home")
+	}
+}
+
+// Palettes of the first n colors, by n
+var palettes = map[int][]tcell.Color{}
+
+// Map a truecolor to the nearest of the colors the terminal supports, tcell
+// would do the same but leaves us unaware of colors falling together.
+func fitColor(c tcell.Color, colors int) tcell.Color {
+	if colors >= 1<<24 || !c.IsRGB() {
+		return c
+	}
+	if colors <= 0 {
+		return tcell.ColorDefault
+	}
+	n := util.Cond(colors > 256, 256, colors)
+	palette, ok := palettes[n]
+	if !ok {
+		for i := 0; i < n; i++ {
+			palette = append(palette, tcell.PaletteColor(i))
+		}
+		palettes[n] = palette
+	}
+	return tcell.FindColor(c, palette)
+}
+
+// Theme with colors fitted to the terminal
+func (t BoardTheme) fit(colors int) BoardTheme {
+	for _, c := range []*tcell.Color{
+		&t.GridFG, &t.BoardBG, &t.LastBlackBG, &t.LastWhiteBG,
+		&t.CursorBlack, &t.CursorWhite,
+		&t.TerritoryBlackBG, &t.TerritoryWhiteBG, &t.DeadBG,
+	} {
+		*c = fitColor(*c, colors)
+	}
+	return t
+}
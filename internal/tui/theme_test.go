@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPruneGameThemes(t *testing.T) {
+	tests := []struct {
+		name   string
+		themes map[int64]string
+		limit  int
+		want   map[int64]string
+	}{
+		{"under limit", map[int64]string{1: "oak", 2: "night"}, 3, map[int64]string{1: "oak", 2: "night"}},
+		{"at limit", map[int64]string{1: "oak", 2: "night"}, 2, map[int64]string{1: "oak", 2: "night"}},
+		{"oldest dropped", map[int64]string{30: "oak", 10: "night", 20: "paper"}, 2, map[int64]string{20: "paper", 30: "oak"}},
+	}
+	for _, tc := range tests {
+		pruneGameThemes(tc.themes, tc.limit)
+		if !reflect.DeepEqual(tc.themes, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, tc.themes, tc.want)
+		}
+	}
+}
+
+func TestUISchemeRoles(t *testing.T) {
+	for name, s := range uiSchemes {
+		for role, c := range map[string]interface{ Valid() bool }{
+			"highlight":         s.HighlightColor,
+			"notification":      s.NotificationColor,
+			"notification text": s.NotificationTextColor,
+			"success":           s.SuccessColor,
+		} {
+			if !c.Valid() {
+				t.Errorf("scheme %s has no %s color", name, role)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/util"
+)
+
+const (
+	tvDefaultInterval = 5 * time.Minute
+	tvFinishedDelay   = 15 * time.Second // Let the result sink in
+	tvCandidates      = 10
+)
+
+// TV mode follows top live games one after another, rotating to the next when
+// the current one finishes or the interval passes. It is owned by the UI
+// goroutine, timers and queries hop back there via app.redraw().
+type tvMode struct {
+	interval time.Duration
+	gameID   int64 // Game being followed
+	timer    *time.Timer
+	watched  map[int64]bool // Followed already, avoid repeating
+}
+
+// Start TV mode right after logged in instead of showing the home page
+func (app *App) SetTVMode(interval time.Duration) {
+	app.tvInterval = interval
+	app.tvOnStart = true
+}
+
+func (app *App) startTV() {
+	app.stopTV()
+	interval := util.Cond(app.tvInterval > 0, app.tvInterval, tvDefaultInterval)
+	app.tv = &tvMode{
+		interval: interval,
+		watched:  make(map[int64]bool),
+	}
+	app.info("TV mode started, rotating every %s", interval)
+	app.tvNext(app.tv)
+}
+
+func (app *App) stopTV() {
+	tv := app.tv
+	if tv == nil {
+		return
+	}
+	app.tv = nil
+	if tv.timer != nil {
+		tv.timer.Stop()
+	}
+	app.info("TV mode stopped")
+}
+
+// Whether the game is being followed by TV mode
+func (app *App) onTV(gameID int64) bool {
+	return app.tv != nil && app.tv.gameID == gameID
+}
+
+// Called by game page when game phase changes
+func (app *App) tvGamePhase(gameID int64, phase googs.GamePhase) {
+	if phase != googs.FinishedPhase || !app.onTV(gameID) {
+		return
+	}
+	app.info("TV game %d finished, next game in %s", gameID, tvFinishedDelay)
+	app.tvSchedule(app.tv, tvFinishedDelay)
+}
+
+func (app *App) tvSchedule(tv *tvMode, delay time.Duration) {
+	if tv.timer != nil {
+		tv.timer.Stop()
+	}
+	tv.timer = time.AfterFunc(delay, func() {
+		app.redraw(func() { app.tvNext(tv) })
+	})
+}
+
+// Pick the top live game not followed yet and switch to it. The query is done
+// in background, nothing happens if TV mode is stopped or restarted meanwhile.
+func (app *App) tvNext(tv *tvMode) {
+	if app.tv != tv {
+		return
+	}
+
+	// Stop if user has navigated away from the followed game
+	current := tv.gameID
+	if front, _ := app.root.GetFrontPage(); current != 0 && front != fmt.Sprintf("%d", current) {
+		app.stopTV()
+		return
+	}
+
+	go func() {
+		// Prefer games of human players, fall back to any
+		resp, err := app.client.GameListQuery(googs.LiveGameList, 0, tvCandidates, &googs.GameListWhere{HideBotGames: true}, 10*time.Second)
+		if err == nil && len(resp.Results) == 0 {
+			resp, err = app.client.GameListQuery(googs.LiveGameList, 0, tvCandidates, nil, 10*time.Second)
+		}
+		app.redraw(func() {
+			if app.tv != tv {
+				return
+			}
+			if err != nil {
+				app.error("TV mode query games %v", err)
+				app.tvSchedule(tv, time.Minute)
+				return
+			}
+			app.tvSwitch(tv, current, resp.Results)
+		})
+	}()
+}
+
+func (app *App) tvSwitch(tv *tvMode, current int64, candidates []googs.GameListEntry) {
+	var next int64
+	for _, g := range candidates {
+		if g.Phase == googs.PlayPhase && g.ID != current && !tv.watched[g.ID] {
+			next = g.ID
+			break
+		}
+	}
+	if next == 0 && len(candidates) > 0 {
+		// Seen all, start over
+		tv.watched = make(map[int64]bool)
+		next = candidates[0].ID
+	}
+	tv.gameID = next
+	tv.watched[next] = true
+	app.tvSchedule(tv, tv.interval)
+
+	if next == 0 || next == current {
+		return
+	}
+	app.info("TV mode switching to game %d", next)
+	if p, ok := app.pages[fmt.Sprintf("%d", current)].(*gamePage); ok {
+		p.close(app)
+	}
+	app.switchToNewGamePage(next, "watch")
+}
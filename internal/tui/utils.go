@@ -7,14 +7,6 @@ import (
 	"github.com/rivo/uniseg"
 )
 
-// Equivalent to Python `return x if b else y`
-func cond[T any](b bool, x, y T) T {
-	if b {
-		return x
-	}
-	return y
-}
-
 // Trim a unicode string up to given max display width
 func trimString(s string, maxWidth int) string {
 	var b strings.Builder
@@ -32,23 +24,30 @@ func trimString(s string, maxWidth int) string {
 	return b.String()
 }
 
-func keyHint(desc string) string {
-	parts := strings.SplitN(desc, " ", 2)
-	if len(parts) > 1 {
-		// "CR play" => "[::r]CR[::-] play"
-		return fmt.Sprintf("[::r]%s[::-] %s", parts[0], parts[1])
+// Render hints of the scope with common hints appended. An item with a space
+// is shown as is with its first word as the key, e.g. "CR connect" for keys
+// not in the keymap, others are actions, see keymap.hint().
+func (app *App) keyHints(scope string, items ...string) string {
+	var hints []string
+	items = append(items, commonHints...)
+	for _, item := range items {
+		if key, desc, ok := strings.Cut(item, " "); ok {
+			hints = append(hints, fmt.Sprintf("[::r]%s[::-] %s", key, desc))
+		} else if hint := app.keyHint(scope, item); hint != "" {
+			hints = append(hints, hint)
+		}
 	}
-	// "Pass" => "[::r]P[::-]ass"
-	return fmt.Sprintf("[::r]%c[::-]%s", desc[0], desc[1:])
+	return strings.Join(hints, " ⋅ ")
 }
 
-func keyHints(descs []string) string {
-	var hints []string
-	descs = append(descs, commonKeyDescriptions...)
-	for _, desc := range descs {
-		hints = append(hints, keyHint(desc))
+// Hint of the actions in the scope, see keymap.hint(). Empty if unbound, or
+// an action is unknown which is logged as an error.
+func (app *App) keyHint(scope, actions string) string {
+	hint, err := app.keys.hint(scope, actions)
+	if err != nil {
+		app.error("Key hint %v", err)
 	}
-	return strings.Join(hints, " ⋅ ")
+	return hint
 }
 
 // Note only 1-20 and 21-35, 36-50 are continuous respectively.
@@ -2,49 +2,90 @@ package tui
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/ymattw/googs"
+
+	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/util"
 )
 
 type watchPage struct {
-	grid   *tview.Grid
-	next   *tview.Button
-	home   *tview.Button
-	logout *tview.Button
-	games  *tview.Table
-	status *tview.TextView
-	hint   *tview.TextView
-
-	ticker   *time.Ticker
+	grid    *tview.Grid
+	next    *tview.Button
+	home    *tview.Button
+	history *tview.Button
+	logout  *tview.Button
+	games   *tview.Table
+	status  *tview.TextView
+	hint    *tview.TextView
+
+	done     chan struct{} // Closed to stop background work
+	listType googs.GameListType
+	from     int // Offset of current page
+	filter   config.GameFilter
 	gameList *googs.GameListResponse
+	shown    []*googs.GameListEntry // Filtered
+
+	// Auto refresh, delay backs off on errors
+	autoRefresh  bool
+	refreshDelay time.Duration
+	nextRefresh  time.Time
+
+	// Results of the previous fetch of the same query, for change marks
+	query    string
+	previous map[int64]googs.GameListEntry
 }
 
+const (
+	watchPageSize        = 20
+	watchRefreshMaxDelay = 5 * time.Minute
+)
+
 func newWatchPage(app *App) Page {
 	p := &watchPage{
-		grid:   tview.NewGrid(),
-		next:   tview.NewButton("Next (0)"),
-		home:   tview.NewButton("Home"),
-		logout: tview.NewButton("Logout"),
-		games:  tview.NewTable(),
-		status: tview.NewTextView(),
-		hint:   tview.NewTextView(),
-		ticker: time.NewTicker(time.Second),
+		grid:    tview.NewGrid(),
+		next:    tview.NewButton("Next (0)"),
+		home:    tview.NewButton("Home"),
+		history: tview.NewButton("History"),
+		logout:  tview.NewButton("Logout"),
+		games:   tview.NewTable(),
+		status:  tview.NewTextView(),
+		hint:    tview.NewTextView(),
+		done:    make(chan struct{}),
+
+		listType: googs.LiveGameList,
 	}
 
-	go func() {
-		for range p.ticker.C {
-			// Do not Refresh() here otherwise too many requests
-			newLabel := fmt.Sprintf("Next (%d)", len(app.nextBoard))
-			if newLabel != p.next.GetLabel() {
-				app.redraw(func() {
-					p.next.SetLabel(newLabel)
+	go func(done <-chan struct{}) {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// Do not Refresh() here unless opted in, otherwise too many
+				// requests
+				newLabel := fmt.Sprintf("Next (%d)", len(app.nextBoard))
+				if newLabel != p.next.GetLabel() {
+					app.redraw(func() {
+						p.next.SetLabel(newLabel)
+					})
+				}
+				// Page states are owned by the UI goroutine
+				app.tui.QueueUpdate(func() {
+					if front, _ := app.root.GetFrontPage(); front == "watch" && p.autoRefresh && time.Now().After(p.nextRefresh) {
+						p.refreshInBackground(app)
+					}
 				})
 			}
 		}
-	}()
+	}(p.done)
 
 	p.next.SetSelectedFunc(func() {
 		if g := app.nextGameEntry(); g != nil {
@@ -54,14 +95,19 @@ func newWatchPage(app *App) Page {
 	p.home.SetSelectedFunc(func() {
 		app.switchToPage("home")
 	})
+	p.history.SetSelectedFunc(func() {
+		app.switchToPage("history")
+	})
 	p.logout.SetSelectedFunc(logoutFunc(app))
 
 	navbar := tview.NewFlex().SetDirection(tview.FlexColumn).
-		AddItem(nil, 0, 1, false). // left spacer
+		AddItem(app.navStatus, 0, 1, false). // left spacer
 		AddItem(p.next, 10, 0, false).
 		AddItem(nil, 1, 0, false). // gap
 		AddItem(p.home, 10, 0, false).
 		AddItem(nil, 1, 0, false). // gap
+		AddItem(p.history, 10, 0, false).
+		AddItem(nil, 1, 0, false). // gap
 		AddItem(p.logout, 10, 0, false)
 
 	p.games.SetSelectable(true, false).
@@ -73,10 +119,10 @@ func newWatchPage(app *App) Page {
 	p.hint.SetDynamicColors(true).
 		SetTextColor(Styles.SecondaryTextColor).
 		SetTextAlign(tview.AlignCenter).
-		SetText(keyHints([]string{"↓↑jk select", "CR connect"}))
+		SetText(app.keyHints("watch", "↓↑jk select", "CR connect", "list", "filter", "follow/unfollow", "next_page/prev_page", "auto_refresh", "tv"))
 
-	// Center align the game table and bottom hint in a 4x1 grid
-	p.grid.SetRows(1, 0, 1, 1)
+	// Center align the game table and bottom hint (may wrap) in a 4x1 grid
+	p.grid.SetRows(1, 0, 1, 2)
 	p.grid.SetColumns(0)
 	// Row 0: navbar
 	p.grid.AddItem(navbar, 0, 0, 1, 1, 1, 0, false)
@@ -96,21 +142,78 @@ func (p *watchPage) Root() tview.Primitive {
 }
 
 func (p *watchPage) Focusables() []tview.Primitive {
-	return []tview.Primitive{p.games, p.next, p.home, p.logout}
+	return []tview.Primitive{p.games, p.next, p.home, p.history, p.logout}
 }
 
 func (p *watchPage) Refresh(app *App) error {
 	if !app.client.LoggedIn() {
 		return nil
 	}
+	resp, err := p.fetch(app, p.listType, p.from, p.filter)
+	if err != nil {
+		return err
+	}
+	p.apply(resp, p.queryOf(app))
+	return nil
+}
 
-	resp, err := app.client.GameListQuery(googs.LiveGameList, 0, 20, nil, time.Second*10)
+func (p *watchPage) fetch(app *App, listType googs.GameListType, from int, filter config.GameFilter) (*googs.GameListResponse, error) {
+	var resp *googs.GameListResponse
+	var err error
+	if listType == followingList {
+		resp, err = app.followedGames(gameListWhere(&filter))
+	} else {
+		resp, err = app.client.GameListQuery(listType, from, watchPageSize, gameListWhere(&filter), time.Second*10)
+	}
 	if err != nil {
 		app.error("Refresh watch page %v", err)
-		return err
 	}
+	return resp, err
+}
+
+// Changes are only meaningful between fetches of the same query
+func (p *watchPage) queryOf(app *App) string {
+	return fmt.Sprintf("%s/%d/%+v/%v", p.listType, p.from, p.filter, app.followedIDs())
+}
+
+func (p *watchPage) apply(resp *googs.GameListResponse, query string) {
+	p.previous = nil
+	if p.gameList != nil && query == p.query {
+		p.previous = make(map[int64]googs.GameListEntry)
+		for _, g := range p.gameList.Results {
+			p.previous[g.ID] = g
+		}
+	}
+	p.query = query
 	p.gameList = resp
-	return nil
+}
+
+// Called on the UI goroutine when auto refresh is due. The list is fetched in
+// background and applied in app.redraw(), unless the query changed meanwhile.
+func (p *watchPage) refreshInBackground(app *App) {
+	if !app.client.LoggedIn() {
+		return
+	}
+	listType, from, filter := p.listType, p.from, p.filter
+	query := p.queryOf(app)
+	p.nextRefresh = time.Now().Add(watchRefreshMaxDelay) // Not due again while fetching
+
+	go func() {
+		resp, err := p.fetch(app, listType, from, filter)
+		app.redraw(func() {
+			if err != nil {
+				p.refreshDelay = util.Cond(p.refreshDelay*2 < watchRefreshMaxDelay, p.refreshDelay*2, watchRefreshMaxDelay)
+				app.warn("Auto refresh watch page failed, retry in %s", p.refreshDelay)
+			} else {
+				p.refreshDelay = app.cfg.UI.Refresh.Watch.Std()
+				if query == p.queryOf(app) {
+					p.apply(resp, query)
+				}
+			}
+			p.nextRefresh = time.Now().Add(p.refreshDelay)
+			p.Render(app)
+		})
+	}()
 }
 
 func (p *watchPage) Render(app *App) {
@@ -118,15 +221,59 @@ func (p *watchPage) Render(app *App) {
 		return
 	}
 
+	// Keep selected game across refreshes
+	var selectedID int64
+	if row, _ := p.games.GetSelection(); row >= 1 && row <= len(p.shown) {
+		selectedID = p.shown[row-1].ID
+	}
+
 	p.games.Clear()
 	p.games.Select(-1, -1)
 	if p.gameList == nil {
 		p.status.SetText("[red]Query game list got null response[-]")
 		return
 	}
-	p.status.SetText(fmt.Sprintf("Showing top %d of total %d %s games", len(p.gameList.Results), p.gameList.Size, p.gameList.List))
 
-	p.games.SetTitle(fmt.Sprintf(" Live Games (%d) ", len(p.gameList.Results)))
+	p.shown = nil
+	for i := range p.gameList.Results {
+		if matchListEntry(&p.filter, &p.gameList.Results[i]) {
+			p.shown = append(p.shown, &p.gameList.Results[i])
+		}
+	}
+
+	var status string
+	if p.listType == followingList {
+		status = fmt.Sprintf("Following %d players, %d games", len(app.following()), p.gameList.Size)
+	} else {
+		listName := util.Cond(p.listType == googs.LiveGameList, "live", "correspondence")
+		pages := (p.gameList.Size + watchPageSize - 1) / watchPageSize
+		status = fmt.Sprintf("Page %d of %d, total %d %s games", p.from/watchPageSize+1, util.Cond(pages > 0, pages, 1), p.gameList.Size, listName)
+	}
+	// The server filters before paging, the rest is filtered here within
+	// the page, so a page may show fewer games
+	local := localFilterString(&p.filter)
+	if f := filterString(&p.filter); f != "" {
+		status += fmt.Sprintf(", filtered by %s", f)
+	}
+	if local != "" {
+		status += fmt.Sprintf(", %d of %d on this page match %s", len(p.shown), len(p.gameList.Results), local)
+	}
+	if p.autoRefresh {
+		status += util.Cond(p.refreshDelay > app.cfg.UI.Refresh.Watch.Std(),
+			fmt.Sprintf(", [red]auto refresh failed, retry in %s[-]", p.refreshDelay),
+			fmt.Sprintf(", auto refresh every %s", app.cfg.UI.Refresh.Watch.Std()))
+	}
+
+	title := map[googs.GameListType]string{
+		googs.LiveGameList:           "Live",
+		googs.CorrespondenceGameList: "Correspondence",
+		followingList:                "Following",
+	}[p.listType]
+	count := fmt.Sprintf("%d", len(p.shown))
+	if local != "" {
+		count = fmt.Sprintf("%d of %d on page", len(p.shown), len(p.gameList.Results))
+	}
+	p.games.SetTitle(fmt.Sprintf(" %s Games (%s) ", title, count))
 
 	// Headers
 	headers := []string{"Move", "Game", "Flags", "Black", "White", "Handicap", "Komi", "Phase", "Size"}
@@ -135,13 +282,21 @@ func (p *watchPage) Render(app *App) {
 	}
 
 	// Rows
-	for i, g := range p.gameList.Results {
+	var added, changed int
+	for i, g := range p.shown {
+		prev, seen := p.previous[g.ID]
+		isNew := p.previous != nil && !seen
+		isChanged := seen && (prev.MoveNumber != g.MoveNumber || prev.Phase != g.Phase)
+		added += util.Cond(isNew, 1, 0)
+		changed += util.Cond(isChanged, 1, 0)
+
 		p.games.SetCell(i+1, 0, tview.NewTableCell(fmt.Sprintf("%3d", g.MoveNumber)))
 		p.games.SetCell(i+1, 1, tview.NewTableCell(trimString(g.Name, 30)))
-		handicap := cond(g.Handicap > 0, "🤏", "")
-		bot := cond(g.BotGame, "🤖", "")
-		private := cond(g.Private, "🔒", "")
-		p.games.SetCell(i+1, 2, tview.NewTableCell(handicap+bot+private))
+		fresh := util.Cond(isNew, "🆕", "")
+		handicap := util.Cond(g.Handicap > 0, "🤏", "")
+		bot := util.Cond(g.BotGame, "🤖", "")
+		private := util.Cond(g.Private, "🔒", "")
+		p.games.SetCell(i+1, 2, tview.NewTableCell(fresh+handicap+bot+private))
 		p.games.SetCell(i+1, 3, tview.NewTableCell(g.Black.String()))
 		p.games.SetCell(i+1, 4, tview.NewTableCell(g.White.String()))
 		p.games.SetCell(i+1, 5, tview.NewTableCell(fmt.Sprintf("%d", g.Handicap)))
@@ -155,30 +310,157 @@ func (p *watchPage) Render(app *App) {
 				p.games.GetCell(i+1, col).SetTextColor(Styles.TertiaryTextColor)
 			}
 		}
+		if isChanged || g.Phase == googs.FinishedPhase {
+			color := util.Cond(g.Phase == googs.FinishedPhase, Colors.SuccessColor, Colors.HighlightColor)
+			for col := range headers {
+				p.games.GetCell(i+1, col).SetTextColor(color)
+			}
+		}
+		if g.ID == selectedID {
+			p.games.Select(i+1, 0)
+		}
 	}
 
+	// Games gone from the list since last fetch, mostly finished
+	current := make(map[int64]bool)
+	for _, g := range p.gameList.Results {
+		current[g.ID] = true
+	}
+	var gone []googs.GameListEntry
+	for id, g := range p.previous {
+		if !current[id] {
+			gone = append(gone, g)
+		}
+	}
+	sort.Slice(gone, func(i, j int) bool { return gone[i].ID < gone[j].ID })
+	for i, g := range gone {
+		cells := []string{fmt.Sprintf("%3d", g.MoveNumber), trimString(g.Name, 30), "", g.Black.String(), g.White.String(),
+			fmt.Sprintf("%d", g.Handicap), fmt.Sprintf("%.1f", g.Komi), "gone", fmt.Sprintf("%dx%d ", g.Width, g.Height)}
+		for col, text := range cells {
+			p.games.SetCell(len(p.shown)+1+i, col, tview.NewTableCell(text).
+				SetSelectable(false).
+				SetTextColor(Styles.MoreContrastBackgroundColor))
+		}
+	}
+	if p.previous != nil {
+		status += fmt.Sprintf(", %d new, %d changed, %d gone", added, changed, len(gone))
+	}
+	p.status.SetText(status)
+
 	p.games.SetSelectedFunc(func(row, _ int) {
-		if len(p.gameList.Results) < 1 {
+		if len(p.shown) < 1 {
 			return
 		}
-		selected := p.gameList.Results[row-1]
+		selected := p.shown[row-1]
 		p.status.SetText(fmt.Sprintf("Connecting to game %d ...", selected.ID))
 		app.switchToNewGamePage(selected.ID, "")
 	})
 }
 
+// Stop background work when the page is discarded
+func (p *watchPage) stop(app *App) {
+	close(p.done)
+}
+
 func (p *watchPage) Leave(app *App) {
 	app.tui.Stop()
 }
 
 func (p *watchPage) setupKeys(app *App) {
+	// Fetch the query, which becomes current on the UI goroutine once fetched
+	load := func(listType googs.GameListType, from int, filter config.GameFilter) {
+		var resp *googs.GameListResponse
+		app.loading(
+			func() (err error) {
+				resp, err = p.fetch(app, listType, from, filter)
+				return err
+			},
+			func() {
+				p.listType, p.from, p.filter = listType, from, filter
+				p.apply(resp, p.queryOf(app))
+				p.Render(app)
+			},
+		)
+	}
+	reload := func() {
+		load(p.listType, p.from, p.filter)
+	}
+
 	p.games.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Rune() {
-		case 'r':
-			app.loading(
-				func() error { return p.Refresh(app) },
-				func() { p.Render(app) },
-			)
+		switch app.keys.action("watch", event) {
+		case "refresh":
+			reload()
+			return nil
+		case "auto_refresh":
+			p.autoRefresh = !p.autoRefresh
+			p.refreshDelay = app.cfg.UI.Refresh.Watch.Std()
+			p.nextRefresh = time.Now().Add(p.refreshDelay)
+			app.info("Auto refresh watch page %s", util.Cond(p.autoRefresh, "enabled", "disabled"))
+			p.Render(app)
+			return nil
+		case "tv":
+			app.startTV()
+			return nil
+		case "list":
+			listType := map[googs.GameListType]googs.GameListType{
+				googs.LiveGameList:           googs.CorrespondenceGameList,
+				googs.CorrespondenceGameList: followingList,
+				followingList:                googs.LiveGameList,
+			}[p.listType]
+			load(listType, 0, p.filter)
+			return nil
+		case "follow":
+			app.prompt("Follow player", "Username or ID ", func(nameOrID string) {
+				if strings.TrimSpace(nameOrID) == "" {
+					return
+				}
+				app.loading(
+					func() error { return app.follow(nameOrID) },
+					func() {
+						if p.listType == followingList {
+							reload()
+						} else {
+							p.Render(app)
+						}
+					},
+				)
+			})
+			return nil
+		case "unfollow":
+			following := app.following()
+			if len(following) == 0 {
+				return nil
+			}
+			buttons := []string{}
+			callbacks := map[string]func(){}
+			for _, f := range following {
+				id := f.ID
+				buttons = append(buttons, f.Username)
+				callbacks[f.Username] = func() {
+					app.unfollow(id)
+					if p.listType == followingList {
+						reload()
+					}
+				}
+			}
+			app.popUp("Unfollow player", append(buttons, "Cancel"), callbacks)
+			return nil
+		case "filter":
+			fields := []string{filterFieldSize, filterFieldRanked, filterFieldSpeed, filterFieldBots, filterFieldRank}
+			filter := p.filter
+			app.editFilter(&filter, fields, func() {
+				load(p.listType, 0, filter)
+			})
+			return nil
+		case "next_page":
+			if p.listType != followingList && p.gameList != nil && p.from+watchPageSize < p.gameList.Size {
+				load(p.listType, p.from+watchPageSize, p.filter)
+			}
+			return nil
+		case "prev_page":
+			if p.listType != followingList && p.from > 0 {
+				load(p.listType, p.from-watchPageSize, p.filter)
+			}
 			return nil
 		}
 		return event
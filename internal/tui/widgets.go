@@ -1,12 +1,33 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/rivo/uniseg"
+
+	"github.com/ymattw/tenuki/internal/ogs"
 )
 
-func (app *App) popUp(message string, buttons []string, callbacks map[string]func()) *tview.Modal {
+const notificationDuration = 8 * time.Second
+
+type notification struct {
+	message string
+	expiry  time.Time
+}
+
+// Notifications are drawn on top of whatever page without taking focus
+type notifications struct {
+	list []notification
+	lock sync.Mutex
+}
+
+// Pop up a modal over the front page, returns the page name of the modal
+func (app *App) popUp(message string, buttons []string, callbacks map[string]func()) string {
 	returnPage, _ := app.root.GetFrontPage()
 	returnFocus := app.tui.GetFocus()
 	popupPage := returnPage + "-popup"
@@ -23,7 +44,7 @@ func (app *App) popUp(message string, buttons []string, callbacks map[string]fun
 		})
 	app.root.AddPage(popupPage, modal, false, true)
 	app.tui.SetFocus(modal)
-	return modal
+	return popupPage
 }
 
 func (app *App) confirm(message string, callback func()) {
@@ -36,12 +57,21 @@ func (app *App) loading(refresh func() error, render func()) {
 
 	// Do background work
 	go func() {
+		token := app.client.AccessToken
 		err := refresh()
+		if ogs.IsAuthError(err) {
+			if err = app.reauthenticate(token); err == nil {
+				err = refresh()
+			}
+		}
 		close(done)
 		app.root.RemovePage(pageName) // Safe to do even when not shown
 
 		app.redraw(func() {
-			if err != nil {
+			if errors.Is(err, errLoginNeeded) {
+				front, _ := app.root.GetFrontPage()
+				app.relogin(front)
+			} else if err != nil {
 				app.popUp("[red]"+err.Error(), []string{"OK"}, nil)
 			} else {
 				render()
@@ -61,3 +91,88 @@ func (app *App) loading(refresh func() error, render func()) {
 		timer.Stop()
 	}
 }
+
+// Pop up a form to input a line of text, callback is called with the text
+// when submitted.
+func (app *App) prompt(title, label string, callback func(string)) {
+	app.promptField(title, tview.NewInputField().SetLabel(label), callback)
+}
+
+// Same as prompt, with the input masked
+func (app *App) promptPassword(title, label string, callback func(string)) {
+	app.promptField(title, tview.NewInputField().SetLabel(label).SetMaskCharacter('*'), callback)
+}
+
+func (app *App) promptField(title string, field *tview.InputField, callback func(string)) {
+	returnPage, _ := app.root.GetFrontPage()
+	returnFocus := app.tui.GetFocus()
+	pageName := returnPage + "-prompt"
+	dismiss := func() {
+		app.root.RemovePage(pageName)
+		app.root.SwitchToPage(returnPage)
+		app.tui.SetFocus(returnFocus)
+	}
+
+	field.SetFieldWidth(24)
+	form := tview.NewForm().
+		AddFormItem(field)
+	form.SetButtonsAlign(tview.AlignCenter).
+		AddButton("OK", func() {
+			dismiss()
+			callback(field.GetText())
+		}).
+		AddButton("Cancel", dismiss).
+		SetCancelFunc(dismiss).
+		SetTitle(" " + title + " ").
+		SetBorder(true)
+
+	// Center align the form in a 3x3 grid
+	grid := tview.NewGrid().
+		SetRows(-1, 7, -1).
+		SetColumns(-1, 48, -1).
+		AddItem(form, 1, 1, 1, 1, 0, 0, true)
+	app.root.AddPage(pageName, grid, true, true)
+	app.tui.SetFocus(form)
+}
+
+// Show a message at top right corner for a while, safe to call from anywhere
+func (app *App) notify(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	app.info("Notification: %s", message)
+
+	app.notifications.lock.Lock()
+	app.notifications.list = append(app.notifications.list, notification{
+		message: message,
+		expiry:  time.Now().Add(notificationDuration),
+	})
+	app.notifications.lock.Unlock()
+
+	app.redraw(nil)
+	time.AfterFunc(notificationDuration, func() { app.redraw(nil) })
+}
+
+// Called after each screen draw
+func (app *App) drawNotifications(screen tcell.Screen) {
+	app.notifications.lock.Lock()
+	defer app.notifications.lock.Unlock()
+
+	var shown []notification
+	for _, n := range app.notifications.list {
+		if time.Now().Before(n.expiry) {
+			shown = append(shown, n)
+		}
+	}
+	app.notifications.list = shown
+
+	w, _ := screen.Size()
+	style := StyleDefault.Background(Colors.NotificationColor)
+	for i, n := range shown {
+		text := trimString(" "+n.message+" ", w/2)
+		width := uniseg.StringWidth(text)
+		x, y := w-width-1, 1+i
+		for col := x; col < x+width; col++ {
+			screen.SetContent(col, y, ' ', nil, style)
+		}
+		tview.Print(screen, tview.Escape(text), x, y, width, tview.AlignLeft, Colors.NotificationTextColor)
+	}
+}
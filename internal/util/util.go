@@ -0,0 +1,10 @@
+// Package util has small helpers shared by other packages.
+package util
+
+// Cond is equivalent to Python `return x if b else y`.
+func Cond[T any](b bool, x, y T) T {
+	if b {
+		return x
+	}
+	return y
+}
@@ -7,15 +7,22 @@ import (
 	"log"
 	"os"
 
-	"github.com/ymattw/googs"
+	"golang.org/x/term"
 
+	"github.com/ymattw/tenuki/internal/cli"
 	"github.com/ymattw/tenuki/internal/config"
+	"github.com/ymattw/tenuki/internal/daemon"
+	"github.com/ymattw/tenuki/internal/ogs"
 	"github.com/ymattw/tenuki/internal/tui"
 )
 
 var (
 	showVersion = flag.Bool("V", false, "Print version and exit")
-	username    = flag.String("u", "", "OGS username, only needed for switching accounts")
+	username    = flag.String("u", "", "OGS username, to skip the account picker when multiple are saved")
+	daemonMode  = flag.Bool("daemon", false, "Run headless, running hooks of "+config.ConfigPath()+" on game events")
+	tvInterval  = flag.Duration("tv", 0, "Start in TV mode following top live games, rotating at given interval (e.g. 5m)")
+	server      = flag.String("server", "", "Host of an OGS compatible server (e.g. beta.online-go.com), default from config or "+config.DefaultServer)
+	encrypt     = flag.Bool("encrypt-secret", false, "Encrypt saved secret files with a passphrase ($"+config.PassphraseEnv+" or prompted) and exit")
 
 	// To be set by compiler via -ldflags
 	buildVersion string
@@ -24,37 +31,147 @@ var (
 )
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: tenuki [flags] [subcommand]\n\nFlags:\n")
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output())
+		cli.Usage(flag.CommandLine.Output())
+	}
 	flag.Parse()
 	if *showVersion {
 		fmt.Printf("Tenuki version: %s\nbuilt on: %s\ncommit: %s\n",
 			buildVersion, buildDate, buildCommit)
 		os.Exit(0)
 	}
+	if flag.NArg() > 0 && !cli.IsCommand(flag.Arg(0)) {
+		flag.Usage()
+		os.Exit(cli.ExitUsage)
+	}
 
-	client, err := loadClient()
+	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("Invalid config: %v", err)
+	}
+	if *server != "" {
+		if err := cfg.SetServerHost(*server); err != nil {
+			log.Fatalf("Invalid -server: %v", err)
+		}
+	}
+	if *encrypt {
+		if err := encryptSecrets(cfg.Server); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	secretFiles := cfg.Server.SearchSecrets(*username)
+	pickAccount := len(secretFiles) > 1
+	if pickAccount && (flag.NArg() > 0 || *daemonMode) {
+		log.Fatalf("Username (-u) is needed to pick one from multiple secret files found: %q\n", secretFiles)
+	}
+	client, passphrase := ogs.NewClient(cfg.Server, "", ""), ""
+	if len(secretFiles) == 1 {
+		if client, passphrase, err = ogs.LoadClient(cfg.Server, secretFiles[0], readPassphrase); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if flag.NArg() > 0 {
+		code := cli.Run(client, flag.Args())
+		client.Disconnect()
+		os.Exit(code)
+	}
+	if *daemonMode {
+		if !client.LoggedIn() {
+			log.Fatal("Not logged in, run tenuki without -daemon to login first")
+		}
+		if err := daemon.Run(client, cfg); err != nil {
+			log.Fatal(err)
+		}
+		client.Disconnect()
+		return
 	}
 
-	app := tui.NewApp(client)
+	app, err := tui.NewApp(client, cfg)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	if *tvInterval > 0 {
+		app.SetTVMode(*tvInterval)
+	}
+	if pickAccount {
+		app.PickAccountOnStart()
+	}
+	if passphrase == "" {
+		passphrase = os.Getenv(config.PassphraseEnv)
+	}
+	app.SetPassphrase(passphrase)
 	if err := app.Run(); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func loadClient() (*googs.Client, error) {
-	secretFiles := config.SearchSecrets(*username)
-	if len(secretFiles) > 1 {
-		log.Fatalf("Username (-u) is needed to pick one from multiple secret files found: %q\n", secretFiles)
+// Passphrase from the environment, or prompted on the terminal
+func readPassphrase() (string, error) {
+	if p := os.Getenv(config.PassphraseEnv); p != "" {
+		return p, nil
 	}
-	if len(secretFiles) == 0 {
-		return googs.NewClient("", ""), nil
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("secret file is encrypted, set $%s or run on a terminal", config.PassphraseEnv)
 	}
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	p, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return string(p), err
+}
 
-	c, err := googs.LoadClient(secretFiles[0])
-	if err != nil {
-		// Use maybe-set fields from the incomplete client
-		return googs.NewClient(c.ClientID, c.ClientSecret), nil
+// Encrypt plain secret files of the server in place, all found unless -u is
+// given.
+func encryptSecrets(server config.Server) error {
+	var plain []string
+	for _, path := range server.SearchSecrets(*username) {
+		encrypted, err := config.SecretEncrypted(path)
+		if err != nil {
+			return err
+		}
+		if encrypted {
+			fmt.Printf("Already encrypted: %s\n", path)
+		} else {
+			plain = append(plain, path)
+		}
+	}
+	if len(plain) == 0 {
+		return nil
+	}
+
+	passphrase := os.Getenv(config.PassphraseEnv)
+	if passphrase == "" {
+		p, err := readPassphrase()
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(os.Stderr, "Again ")
+		again, err := readPassphrase()
+		if err != nil {
+			return err
+		}
+		if p != again {
+			return fmt.Errorf("passphrases do not match")
+		}
+		passphrase = p
+	}
+	if passphrase == "" {
+		return fmt.Errorf("empty passphrase")
+	}
+
+	for _, path := range plain {
+		data, _, err := config.ReadSecret(path, nil)
+		if err != nil {
+			return err
+		}
+		if err := config.WriteSecret(path, data, passphrase); err != nil {
+			return err
+		}
+		fmt.Printf("Encrypted: %s\n", path)
 	}
-	return c, nil
+	return nil
 }
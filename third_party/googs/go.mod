@@ -0,0 +1,7 @@
+module github.com/ymattw/googs
+
+go 1.18
+
+require github.com/graarh/golang-socketio v0.0.0-20170510162725-2c44953b9b5f
+
+require github.com/gorilla/websocket v1.5.3 // indirect